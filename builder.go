@@ -0,0 +1,339 @@
+package sprig
+
+import (
+	"fmt"
+	"html/template"
+	"math/rand"
+	"os"
+	ttemplate "text/template"
+	"time"
+)
+
+// Builder produces function maps tailored by a set of options.
+//
+// Use New to create a Builder:
+//
+//	fmap := sprig.New(
+//		sprig.ExcludeCategories(sprig.CategoryOS, sprig.CategoryNetwork),
+//		sprig.WithClock(func() time.Time { return fixed }),
+//	).TxtFuncMap()
+type Builder struct {
+	include           map[string]bool
+	exclude           map[string]bool
+	includeCategories map[Category]bool
+	excludeCategories map[Category]bool
+	hermetic          bool
+	strictDates       bool
+
+	clock     func() time.Time
+	rand      *seededRand
+	lookupEnv func(string) (string, bool)
+	resolver  Resolver
+	locale    *Locale
+}
+
+// Option configures a Builder.
+type Option func(*Builder)
+
+// New returns a Builder configured with the given options.
+//
+// Without options the Builder produces the same functions as GenericFuncMap.
+// New panics if IncludeFunctions, ExcludeFunctions, IncludeCategories or
+// ExcludeCategories names a function or category that does not exist, so
+// that a misspelled name is not silently ignored.
+func New(opts ...Option) *Builder {
+	b := &Builder{
+		include:           map[string]bool{},
+		exclude:           map[string]bool{},
+		includeCategories: map[Category]bool{},
+		excludeCategories: map[Category]bool{},
+	}
+	for _, opt := range opts {
+		opt(b)
+	}
+	if err := b.checkNames(); err != nil {
+		panic(err)
+	}
+	return b
+}
+
+// checkNames returns an error for the first unknown function or category
+// selected by the options.
+func (b *Builder) checkNames() error {
+	for _, names := range []map[string]bool{b.include, b.exclude} {
+		for name := range names {
+			if _, ok := genericMap[name]; !ok {
+				return fmt.Errorf("sprig: unknown function %q", name)
+			}
+		}
+	}
+	for _, cats := range []map[Category]bool{b.includeCategories, b.excludeCategories} {
+		for cat := range cats {
+			if _, ok := functionCategories[cat]; !ok {
+				return fmt.Errorf("sprig: unknown category %q", cat)
+			}
+		}
+	}
+	return nil
+}
+
+// IncludeFunctions restricts the function map to the named functions, plus
+// any functions selected by IncludeCategories.
+func IncludeFunctions(names ...string) Option {
+	return func(b *Builder) {
+		for _, name := range names {
+			b.include[name] = true
+		}
+	}
+}
+
+// ExcludeFunctions removes the named functions from the function map.
+func ExcludeFunctions(names ...string) Option {
+	return func(b *Builder) {
+		for _, name := range names {
+			b.exclude[name] = true
+		}
+	}
+}
+
+// IncludeCategories restricts the function map to the functions in the given
+// categories, plus any functions selected by IncludeFunctions.
+func IncludeCategories(cats ...Category) Option {
+	return func(b *Builder) {
+		for _, cat := range cats {
+			b.includeCategories[cat] = true
+		}
+	}
+}
+
+// ExcludeCategories removes the functions in the given categories from the
+// function map.
+func ExcludeCategories(cats ...Category) Option {
+	return func(b *Builder) {
+		for _, cat := range cats {
+			b.excludeCategories[cat] = true
+		}
+	}
+}
+
+// Hermetic removes the functions that are not guaranteed to evaluate to the
+// same result for given input.
+func Hermetic() Option {
+	return func(b *Builder) {
+		b.hermetic = true
+	}
+}
+
+// StrictDates makes ago, date, dateInZone, htmlDate, htmlDateInZone, strftime
+// and dateFormat return an error for dates they cannot convert, like their must*
+// counterparts, instead of using the current time.
+func StrictDates() Option {
+	return func(b *Builder) {
+		b.strictDates = true
+	}
+}
+
+// WithClock sets the function used to obtain the current time. It is used by
+// now, the date and cron functions that default to or compare against the
+// current time, as the start of the validity period of generated
+// certificates, and to check the validity of certificate chains and JSON Web
+// Tokens.
+func WithClock(clock func() time.Time) Option {
+	return func(b *Builder) {
+		b.clock = clock
+	}
+}
+
+// WithRandSource sets the source of the random functions (randAlphaNum,
+// randAlpha, randAscii, randNumeric, randBytes, randInt, shuffle and uuidv4).
+// A seeded source makes their output repeatable. By default the random strings,
+// bytes and UUIDs come from crypto/rand.
+//
+// Do not use a seeded source to generate secrets.
+func WithRandSource(src rand.Source) Option {
+	return func(b *Builder) {
+		b.rand = newSeededRand(src)
+	}
+}
+
+// WithEnv sets the function used to look up environment variables. It has
+// the same signature as os.LookupEnv.
+func WithEnv(lookup func(key string) (string, bool)) Option {
+	return func(b *Builder) {
+		b.lookupEnv = lookup
+	}
+}
+
+// WithResolver sets the resolver used by the network functions. A
+// *net.Resolver configured with a custom Dial function can be used to query a
+// specific DNS server.
+func WithResolver(r Resolver) Option {
+	return func(b *Builder) {
+		b.resolver = r
+	}
+}
+
+// WithHosts makes the network functions resolve host names from a static
+// table mapping names to addresses, so that templates can be rendered without
+// network access. Names missing from the table, SRV and TXT lookups fail.
+func WithHosts(hosts map[string][]string) Option {
+	return func(b *Builder) {
+		b.resolver = staticResolver(hosts)
+	}
+}
+
+// WithLocale sets the words used by humanizeDuration, humanizeTime and
+// humanizeBetween. Words missing from the Locale are taken from the default
+// English locale.
+func WithLocale(l Locale) Option {
+	return func(b *Builder) {
+		b.locale = &l
+	}
+}
+
+// TxtFuncMap returns a 'text/template'.FuncMap
+func (b *Builder) TxtFuncMap() ttemplate.FuncMap {
+	return ttemplate.FuncMap(b.GenericFuncMap())
+}
+
+// HtmlFuncMap returns an 'html/template'.Funcmap
+func (b *Builder) HtmlFuncMap() template.FuncMap {
+	return template.FuncMap(b.GenericFuncMap())
+}
+
+// GenericFuncMap returns the selected functions as a map[string]interface{}.
+func (b *Builder) GenericFuncMap() map[string]interface{} {
+	nonhermetic := make(map[string]bool, len(nonhermeticFunctions))
+	for _, name := range nonhermeticFunctions {
+		nonhermetic[name] = true
+	}
+
+	all := len(b.include) == 0 && len(b.includeCategories) == 0
+	bound := b.boundFuncs()
+	gfm := make(map[string]interface{}, len(genericMap))
+	for name, fn := range genericMap {
+		cat := functionCategory[name]
+		switch {
+		case b.exclude[name], b.excludeCategories[cat]:
+			continue
+		case b.hermetic && nonhermetic[name]:
+			continue
+		case !all && !b.include[name] && !b.includeCategories[cat]:
+			continue
+		}
+		if bfn, ok := bound[name]; ok {
+			fn = bfn
+		}
+		gfm[name] = fn
+	}
+	return gfm
+}
+
+// boundFuncs returns the functions that depend on the clock, random source,
+// environment, resolver or locale configured on the Builder.
+func (b *Builder) boundFuncs() map[string]interface{} {
+	m := map[string]interface{}{}
+	if b.clock != nil {
+		c := clock(b.clock)
+		m["now"] = b.clock
+		m["ago"] = c.dateAgo
+		m["date"] = c.date
+		m["date_in_zone"] = c.dateInZone
+		m["dateInZone"] = c.dateInZone
+		m["durationRound"] = c.durationRound
+		m["htmlDate"] = c.htmlDate
+		m["htmlDateInZone"] = c.htmlDateInZone
+		m["genCA"] = c.generateCertificateAuthority
+		m["genCAWithKey"] = c.generateCertificateAuthorityWithPEMKey
+		m["genSelfSignedCert"] = c.generateSelfSignedCertificate
+		m["genSelfSignedCertWithKey"] = c.generateSelfSignedCertificateWithPEMKey
+		m["genSignedCert"] = c.generateSignedCertificate
+		m["genSignedCertWithKey"] = c.generateSignedCertificateWithPEMKey
+		m["genCAWithOptions"] = c.genCAWithOptions
+		m["genSelfSignedCertWithOptions"] = c.genSelfSignedCertWithOptions
+		m["genSignedCertWithOptions"] = c.genSignedCertWithOptions
+		m["signCSR"] = c.signCSR
+		m["verifyCertChain"] = c.verifyCertChain
+		m["mustVerifyCertChain"] = c.mustVerifyCertChain
+		m["jwtVerify"] = c.jwtVerify
+		m["mustJwtVerify"] = c.mustJwtVerify
+		m["mustAgo"] = c.mustDateAgo
+		m["mustDate"] = c.mustDate
+		m["mustDateInZone"] = c.mustDateInZone
+		m["mustHtmlDate"] = c.mustHtmlDate
+		m["mustHtmlDateInZone"] = c.mustHtmlDateInZone
+		m["strftime"] = c.strftime
+		m["dateFormat"] = c.strftime
+		m["mustStrftime"] = c.mustStrftime
+		m["mustDateFormat"] = c.mustStrftime
+		m["cronNext"] = c.cronNext
+		m["cronPrev"] = c.cronPrev
+	}
+	if b.clock != nil || b.locale != nil {
+		h := systemHumanizer
+		if b.clock != nil {
+			h.clock = b.clock
+		}
+		if b.locale != nil {
+			h.locale = *b.locale
+		}
+		m["humanizeDuration"] = h.humanizeDuration
+		m["humanizeTime"] = h.humanizeTime
+		m["humanizeBetween"] = h.humanizeBetween
+	}
+	if b.strictDates {
+		c := systemClock
+		if b.clock != nil {
+			c = clock(b.clock)
+		}
+		m["ago"] = c.mustDateAgo
+		m["date"] = c.mustDate
+		m["date_in_zone"] = c.mustDateInZone
+		m["dateInZone"] = c.mustDateInZone
+		m["htmlDate"] = c.mustHtmlDate
+		m["htmlDateInZone"] = c.mustHtmlDateInZone
+		m["strftime"] = c.mustStrftime
+		m["dateFormat"] = c.mustStrftime
+	}
+	if b.rand != nil {
+		m["randAlphaNum"] = b.rand.randAlphaNumeric
+		m["randAlpha"] = b.rand.randAlpha
+		m["randAscii"] = b.rand.randAscii
+		m["randNumeric"] = b.rand.randNumeric
+		m["randBytes"] = b.rand.randBytes
+		m["randInt"] = b.rand.randInt
+		m["shuffle"] = b.rand.shuffle
+		m["uuidv4"] = b.rand.uuidv4
+	}
+	if b.lookupEnv != nil {
+		m["env"] = b.getenv
+		m["expandenv"] = func(s string) string { return os.Expand(s, b.getenv) }
+	}
+	if b.resolver != nil || b.rand != nil {
+		l := systemLookup
+		if b.resolver != nil {
+			l.resolver = b.resolver
+		}
+		if b.rand != nil {
+			l.intn = b.rand.rand.Intn
+		}
+		m["getHostByName"] = l.getHostByName
+		m["getHostsByName"] = l.getHostsByName
+		m["lookupIPv4"] = l.lookupIPv4
+		m["lookupIPv6"] = l.lookupIPv6
+		m["lookupSRV"] = l.lookupSRV
+		m["lookupTXT"] = l.lookupTXT
+		m["mustGetHostByName"] = l.mustGetHostByName
+		m["mustGetHostsByName"] = l.mustGetHostsByName
+		m["mustLookupIPv4"] = l.mustLookupIPv4
+		m["mustLookupIPv6"] = l.mustLookupIPv6
+		m["mustLookupSRV"] = l.mustLookupSRV
+		m["mustLookupTXT"] = l.mustLookupTXT
+	}
+	return m
+}
+
+func (b *Builder) getenv(key string) string {
+	v, _ := b.lookupEnv(key)
+	return v
+}
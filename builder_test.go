@@ -0,0 +1,150 @@
+package sprig
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+	"text/template"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewDefaults(t *testing.T) {
+	fm := New().GenericFuncMap()
+	assert.Equal(t, len(genericMap), len(fm))
+}
+
+func TestNewIncludeExclude(t *testing.T) {
+	fm := New(IncludeCategories(CategoryDate), IncludeFunctions("upper")).GenericFuncMap()
+	assert.Contains(t, fm, "now")
+	assert.Contains(t, fm, "dateInZone")
+	assert.Contains(t, fm, "upper")
+	assert.NotContains(t, fm, "lower")
+
+	fm = New(ExcludeCategories(CategoryOS, CategoryNetwork), ExcludeFunctions("upper")).GenericFuncMap()
+	assert.NotContains(t, fm, "env")
+	assert.NotContains(t, fm, "getHostByName")
+	assert.NotContains(t, fm, "upper")
+	assert.Contains(t, fm, "lower")
+
+	// Exclusions win over inclusions.
+	fm = New(IncludeCategories(CategoryOS), ExcludeFunctions("env")).GenericFuncMap()
+	assert.Equal(t, 1, len(fm))
+	assert.Contains(t, fm, "expandenv")
+}
+
+func TestNewUnknownNames(t *testing.T) {
+	assert.PanicsWithError(t, `sprig: unknown function "uppr"`, func() { New(IncludeFunctions("uppr")) })
+	assert.PanicsWithError(t, `sprig: unknown function "lowr"`, func() { New(ExcludeFunctions("lowr")) })
+	assert.PanicsWithError(t, `sprig: unknown category "dates"`, func() { New(IncludeCategories("dates")) })
+	assert.PanicsWithError(t, `sprig: unknown category "strs"`, func() { New(ExcludeCategories("strs")) })
+	assert.NotPanics(t, func() { New(IncludeFunctions("upper"), ExcludeCategories(CategoryOS)) })
+}
+
+func TestNewHermetic(t *testing.T) {
+	fm := New(Hermetic()).GenericFuncMap()
+	assert.Equal(t, len(HermeticTxtFuncMap()), len(fm))
+	assert.NotContains(t, fm, "now")
+	assert.NotContains(t, fm, "uuidv4")
+}
+
+func TestWithClock(t *testing.T) {
+	fixed := time.Date(2020, 2, 29, 12, 0, 0, 0, time.UTC)
+	fm := New(WithClock(func() time.Time { return fixed })).TxtFuncMap()
+	assert.NoError(t, runtFuncs(fm, `{{ now | unixEpoch }}`, "1582977600"))
+	assert.NoError(t, runtFuncs(fm, `{{ dateInZone "2006-01-02 15:04" "not a date" "UTC" }}`, "2020-02-29 12:00"))
+	assert.NoError(t, runtFuncs(fm, `{{ htmlDateInZone "" "UTC" }}`, "2020-02-29"))
+	assert.NoError(t, runtFuncs(fm, `{{ now | dateModify "-2h30m" | ago }}`, "2h30m0s"))
+	assert.NoError(t, runtFuncs(fm, `{{ now | dateModify "-49h" | durationRound }}`, "2d"))
+
+	out, err := runRawFuncs(fm, `{{ (genCA "example.com" 10).Cert }}`)
+	assert.NoError(t, err)
+	block, _ := pem.Decode([]byte(out))
+	if assert.NotNil(t, block) {
+		cert, err := x509.ParseCertificate(block.Bytes)
+		assert.NoError(t, err)
+		assert.Equal(t, fixed, cert.NotBefore)
+		assert.Equal(t, fixed.AddDate(0, 0, 10), cert.NotAfter)
+	}
+}
+
+func TestWithRandSource(t *testing.T) {
+	tpl := "{{ randInt 0 1000000 }}\n{{ shuffle \"Hello World\" }}\n{{ randAlphaNum 8 }}\n{{ randAlpha 8 }}\n" +
+		"{{ randAscii 8 }}\n{{ randNumeric 8 }}\n{{ randBytes 8 }}\n{{ uuidv4 }}"
+	a, err := runRawFuncs(New(WithRandSource(rand.NewSource(42))).TxtFuncMap(), tpl)
+	assert.NoError(t, err)
+	b, err := runRawFuncs(New(WithRandSource(rand.NewSource(42))).TxtFuncMap(), tpl)
+	assert.NoError(t, err)
+	assert.Equal(t, a, b)
+	c, err := runRawFuncs(New(WithRandSource(rand.NewSource(7))).TxtFuncMap(), tpl)
+	assert.NoError(t, err)
+	assert.NotEqual(t, a, c)
+
+	lines := strings.Split(a, "\n")
+	if assert.Len(t, lines, 8) {
+		assert.Len(t, lines[1], 11)
+		assert.Regexp(t, `^[a-zA-Z0-9]{8}$`, lines[2])
+		assert.Regexp(t, `^[a-zA-Z]{8}$`, lines[3])
+		assert.Len(t, lines[4], 8)
+		assert.Regexp(t, `^[0-9]{8}$`, lines[5])
+		assert.Len(t, lines[6], 12)
+		assert.Regexp(t, `^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`, lines[7])
+	}
+}
+
+func TestWithEnv(t *testing.T) {
+	env := map[string]string{"FOO": "bar"}
+	fm := New(WithEnv(func(key string) (string, bool) {
+		v, ok := env[key]
+		return v, ok
+	})).TxtFuncMap()
+	assert.NoError(t, runtFuncs(fm, `{{ env "FOO" }}`, "bar"))
+	assert.NoError(t, runtFuncs(fm, `{{ env "HOME" }}`, ""))
+	assert.NoError(t, runtFuncs(fm, `{{ expandenv "Hello $FOO" }}`, "Hello bar"))
+}
+
+// runtFuncs runs a template with the given function map and checks that the
+// output exactly matches the expected string.
+func runtFuncs(fmap template.FuncMap, tpl, expect string) error {
+	out, err := runRawFuncs(fmap, tpl)
+	if err != nil {
+		return err
+	}
+	if expect != out {
+		return fmt.Errorf("Expected '%s', got '%s'", expect, out)
+	}
+	return nil
+}
+
+// runRawFuncs runs a template with the given function map and returns the result.
+func runRawFuncs(fmap template.FuncMap, tpl string) (string, error) {
+	t := template.Must(template.New("test").Funcs(fmap).Parse(tpl))
+	var b bytes.Buffer
+	if err := t.Execute(&b, nil); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+func TestStrictDates(t *testing.T) {
+	fixed := time.Date(2020, 2, 29, 12, 0, 0, 0, time.UTC)
+	fm := New(StrictDates(), WithClock(func() time.Time { return fixed })).TxtFuncMap()
+	assert.NoError(t, runtFuncs(fm, `{{ dateInZone "2006-01-02" "1582977600" "UTC" }}`, "2020-02-29"))
+	assert.NoError(t, runtFuncs(fm, `{{ ago "2020-02-29T11:00:00Z" }}`, "1h0m0s"))
+	for _, tpl := range []string{
+		`{{ date "2006" "not a date" }}`,
+		`{{ dateInZone "2006" "" "UTC" }}`,
+		`{{ date_in_zone "2006" .Missing "UTC" }}`,
+		`{{ htmlDate "not a date" }}`,
+		`{{ htmlDateInZone "not a date" "UTC" }}`,
+		`{{ ago "not a date" }}`,
+	} {
+		_, err := runRawFuncs(fm, tpl)
+		assert.Error(t, err, tpl)
+	}
+}
@@ -0,0 +1,754 @@
+package sprig
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"net/url"
+	"sort"
+	"time"
+
+	"github.com/spf13/cast"
+)
+
+// parseCert returns the fields of a PEM encoded certificate as a dict,
+// ignoring errors.
+func parseCert(cert interface{}) map[string]interface{} {
+	d, _ := mustParseCert(cert)
+	return d
+}
+
+// mustParseCert returns the fields of a PEM encoded certificate as a dict,
+// returning errors. The certificate may be given as a string or as the result
+// of genCA, genSignedCert and the like. Only the first certificate in the PEM
+// data is parsed.
+func mustParseCert(cert interface{}) (map[string]interface{}, error) {
+	c, err := parseCertPEM("parseCert", cert)
+	if err != nil {
+		return map[string]interface{}{}, err
+	}
+	sha1sum := sha1.Sum(c.Raw)
+	sha256sum := sha256.Sum256(c.Raw)
+	return map[string]interface{}{
+		"subject":            pkixNameDict(c.Subject),
+		"issuer":             pkixNameDict(c.Issuer),
+		"serialNumber":       c.SerialNumber.String(),
+		"version":            c.Version,
+		"dnsNames":           nonNilStrings(c.DNSNames),
+		"ipAddresses":        ipStrings(c),
+		"uris":               uriStrings(c),
+		"emailAddresses":     nonNilStrings(c.EmailAddresses),
+		"notBefore":          c.NotBefore,
+		"notAfter":           c.NotAfter,
+		"isCA":               c.IsCA,
+		"keyUsage":           keyUsageNames(c.KeyUsage),
+		"extKeyUsage":        extKeyUsageNames(c.ExtKeyUsage),
+		"sha1Fingerprint":    hex.EncodeToString(sha1sum[:]),
+		"sha256Fingerprint":  hex.EncodeToString(sha256sum[:]),
+		"publicKeyAlgorithm": c.PublicKeyAlgorithm.String(),
+		"signatureAlgorithm": c.SignatureAlgorithm.String(),
+	}, nil
+}
+
+// parseCertPEM parses the first certificate in PEM data given as a string or
+// a certificate.
+func parseCertPEM(fn string, cert interface{}) (*x509.Certificate, error) {
+	var data string
+	switch v := cert.(type) {
+	case string:
+		data = v
+	case certificate:
+		data = v.Cert
+	case *certificate:
+		data = v.Cert
+	default:
+		return nil, &TypeError{Func: fn, Arg: "certificate", Got: typeName(cert), Want: "PEM string or certificate"}
+	}
+	rest := []byte(data)
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			return nil, &ParseError{Func: fn, Input: data, Err: errors.New("no PEM encoded certificate found")}
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		c, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, &ParseError{Func: fn, Input: data, Err: err}
+		}
+		return c, nil
+	}
+}
+
+func pkixNameDict(n pkix.Name) map[string]interface{} {
+	return map[string]interface{}{
+		"dn":                 n.String(),
+		"commonName":         n.CommonName,
+		"serialNumber":       n.SerialNumber,
+		"organization":       nonNilStrings(n.Organization),
+		"organizationalUnit": nonNilStrings(n.OrganizationalUnit),
+		"country":            nonNilStrings(n.Country),
+		"province":           nonNilStrings(n.Province),
+		"locality":           nonNilStrings(n.Locality),
+		"streetAddress":      nonNilStrings(n.StreetAddress),
+		"postalCode":         nonNilStrings(n.PostalCode),
+	}
+}
+
+func nonNilStrings(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
+
+func ipStrings(c *x509.Certificate) []string {
+	ips := make([]string, len(c.IPAddresses))
+	for i, ip := range c.IPAddresses {
+		ips[i] = ip.String()
+	}
+	return ips
+}
+
+func uriStrings(c *x509.Certificate) []string {
+	uris := make([]string, len(c.URIs))
+	for i, u := range c.URIs {
+		uris[i] = u.String()
+	}
+	return uris
+}
+
+var keyUsages = []struct {
+	usage x509.KeyUsage
+	name  string
+}{
+	{x509.KeyUsageDigitalSignature, "digitalSignature"},
+	{x509.KeyUsageContentCommitment, "contentCommitment"},
+	{x509.KeyUsageKeyEncipherment, "keyEncipherment"},
+	{x509.KeyUsageDataEncipherment, "dataEncipherment"},
+	{x509.KeyUsageKeyAgreement, "keyAgreement"},
+	{x509.KeyUsageCertSign, "certSign"},
+	{x509.KeyUsageCRLSign, "crlSign"},
+	{x509.KeyUsageEncipherOnly, "encipherOnly"},
+	{x509.KeyUsageDecipherOnly, "decipherOnly"},
+}
+
+func keyUsageNames(ku x509.KeyUsage) []string {
+	names := []string{}
+	for _, u := range keyUsages {
+		if ku&u.usage != 0 {
+			names = append(names, u.name)
+		}
+	}
+	return names
+}
+
+var extKeyUsages = map[x509.ExtKeyUsage]string{
+	x509.ExtKeyUsageAny:             "any",
+	x509.ExtKeyUsageServerAuth:      "serverAuth",
+	x509.ExtKeyUsageClientAuth:      "clientAuth",
+	x509.ExtKeyUsageCodeSigning:     "codeSigning",
+	x509.ExtKeyUsageEmailProtection: "emailProtection",
+	x509.ExtKeyUsageIPSECEndSystem:  "ipsecEndSystem",
+	x509.ExtKeyUsageIPSECTunnel:     "ipsecTunnel",
+	x509.ExtKeyUsageIPSECUser:       "ipsecUser",
+	x509.ExtKeyUsageTimeStamping:    "timeStamping",
+	x509.ExtKeyUsageOCSPSigning:     "ocspSigning",
+}
+
+func extKeyUsageNames(eku []x509.ExtKeyUsage) []string {
+	names := []string{}
+	for _, u := range eku {
+		if name, ok := extKeyUsages[u]; ok {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// genCAWithOptions generates a certificate authority from a dict of options.
+// See certTemplate for the options.
+func (c clock) genCAWithOptions(opts map[string]interface{}) (certificate, error) {
+	const fn = "genCAWithOptions"
+	template, priv, err := c.certTemplateAndKey(fn, opts, true)
+	if err != nil {
+		return certificate{}, err
+	}
+	return signCertTemplate(fn, template, priv, template, priv)
+}
+
+// genSelfSignedCertWithOptions generates a self-signed certificate from a dict
+// of options.
+func (c clock) genSelfSignedCertWithOptions(opts map[string]interface{}) (certificate, error) {
+	const fn = "genSelfSignedCertWithOptions"
+	template, priv, err := c.certTemplateAndKey(fn, opts, false)
+	if err != nil {
+		return certificate{}, err
+	}
+	return signCertTemplate(fn, template, priv, template, priv)
+}
+
+// genSignedCertWithOptions generates a certificate from a dict of options and
+// signs it with the given certificate authority.
+func (c clock) genSignedCertWithOptions(opts map[string]interface{}, ca certificate) (certificate, error) {
+	const fn = "genSignedCertWithOptions"
+	parent, signerKey, err := parseCA(fn, ca)
+	if err != nil {
+		return certificate{}, err
+	}
+	template, priv, err := c.certTemplateAndKey(fn, opts, false)
+	if err != nil {
+		return certificate{}, err
+	}
+	return signCertTemplate(fn, template, priv, parent, signerKey)
+}
+
+func signCertTemplate(fn string, template *x509.Certificate, priv crypto.PrivateKey, parent *x509.Certificate, signerKey crypto.PrivateKey) (certificate, error) {
+	var cert certificate
+	var err error
+	cert.Cert, cert.Key, err = getCertAndKey(template, priv, parent, signerKey)
+	if err != nil {
+		return certificate{}, &FuncError{Func: fn, Err: err}
+	}
+	return cert, nil
+}
+
+// parseCA returns the certificate and private key of a certificate authority.
+func parseCA(fn string, ca certificate) (*x509.Certificate, crypto.PrivateKey, error) {
+	cert, err := parseCertPEM(fn, ca.Cert)
+	if err != nil {
+		return nil, nil, err
+	}
+	key, err := parsePrivateKeyPEM(ca.Key)
+	if err != nil {
+		return nil, nil, &FuncError{Func: fn, Err: fmt.Errorf("error parsing private key: %s", err)}
+	}
+	return cert, key, nil
+}
+
+// The options accepted by the certificate functions, grouped by purpose.
+var (
+	subjectOptions = map[string]bool{
+		"commonName": true, "organization": true, "organizationalUnit": true,
+		"country": true, "province": true, "locality": true,
+		"streetAddress": true, "postalCode": true,
+		"dnsNames": true, "ipAddresses": true, "uris": true, "emailAddresses": true,
+	}
+	issueOptions = map[string]bool{
+		"daysValid": true, "notBefore": true, "notAfter": true, "backdate": true,
+		"keyUsage": true, "extKeyUsage": true, "isCA": true, "maxPathLen": true,
+		"serialNumber": true,
+	}
+	keyOptions = map[string]bool{
+		"keyType": true, "keyBits": true, "key": true,
+	}
+)
+
+// checkOptions returns an error for the first option, in sorted order, that
+// is not in any of the allowed sets.
+func checkOptions(fn string, opts map[string]interface{}, allowed ...map[string]bool) error {
+	keys := make([]string, 0, len(opts))
+	for k := range opts {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+next:
+	for _, k := range keys {
+		for _, set := range allowed {
+			if set[k] {
+				continue next
+			}
+		}
+		return &FuncError{Func: fn, Err: fmt.Errorf("unknown option %q", k)}
+	}
+	return nil
+}
+
+// certTemplateAndKey builds a certificate template and private key from a
+// dict of options:
+//
+//   - commonName, organization, organizationalUnit, country, province,
+//     locality, streetAddress, postalCode: the subject
+//   - dnsNames, ipAddresses, uris, emailAddresses: subject alternative names
+//   - daysValid: the validity period in days, 365 by default
+//   - notBefore, notAfter: the validity period as times or date strings in
+//     any format parseDate accepts
+//   - backdate: a duration string such as "1h" subtracted from the current
+//     time for notBefore; numbers are rejected
+//   - keyUsage, extKeyUsage: lists of usage names as reported by parseCert
+//   - isCA, maxPathLen: basic constraints
+//   - serialNumber: a serial number, random by default
+//   - keyType, keyBits: the type of key to generate (rsa, ecdsa or ed25519)
+//     and the size of RSA keys, 2048 by default
+//   - key: a PEM encoded private key to use instead of generating one
+func (c clock) certTemplateAndKey(fn string, opts map[string]interface{}, isCA bool) (*x509.Certificate, crypto.PrivateKey, error) {
+	if err := checkOptions(fn, opts, subjectOptions, issueOptions, keyOptions); err != nil {
+		return nil, nil, err
+	}
+	subject, err := subjectFromOptions(fn, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	priv, err := certKey(fn, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	pub, err := getPublicKey(priv)
+	if err != nil {
+		return nil, nil, &FuncError{Func: fn, Err: err}
+	}
+	template, err := c.certTemplate(fn, opts, isCA, pub)
+	if err != nil {
+		return nil, nil, err
+	}
+	template.Subject = subject.name
+	template.DNSNames = subject.dnsNames
+	template.EmailAddresses = subject.emailAddresses
+	template.IPAddresses = subject.ipAddresses
+	template.URIs = subject.uris
+	return template, priv, nil
+}
+
+// certSubject holds the subject and subject alternative names shared by
+// certificates and certificate requests.
+type certSubject struct {
+	name           pkix.Name
+	dnsNames       []string
+	emailAddresses []string
+	ipAddresses    []net.IP
+	uris           []*url.URL
+}
+
+func subjectFromOptions(fn string, opts map[string]interface{}) (certSubject, error) {
+	subject := certSubject{
+		name: pkix.Name{
+			CommonName:         optString(opts, "commonName"),
+			Organization:       optStrings(opts, "organization"),
+			OrganizationalUnit: optStrings(opts, "organizationalUnit"),
+			Country:            optStrings(opts, "country"),
+			Province:           optStrings(opts, "province"),
+			Locality:           optStrings(opts, "locality"),
+			StreetAddress:      optStrings(opts, "streetAddress"),
+			PostalCode:         optStrings(opts, "postalCode"),
+		},
+		dnsNames:       optStrings(opts, "dnsNames"),
+		emailAddresses: optStrings(opts, "emailAddresses"),
+	}
+	for _, s := range optStrings(opts, "ipAddresses") {
+		ip := net.ParseIP(s)
+		if ip == nil {
+			return certSubject{}, &ParseError{Func: fn, Input: s, Err: errors.New("invalid IP address")}
+		}
+		subject.ipAddresses = append(subject.ipAddresses, ip)
+	}
+	for _, s := range optStrings(opts, "uris") {
+		u, err := url.Parse(s)
+		if err != nil {
+			return certSubject{}, &ParseError{Func: fn, Input: s, Err: err}
+		}
+		subject.uris = append(subject.uris, u)
+	}
+	return subject, nil
+}
+
+// certTemplate builds a certificate template without a subject from the
+// issueOptions for a certificate of the public key pub. As in crypto/tls,
+// the default key usage only includes key encipherment for RSA keys.
+func (c clock) certTemplate(fn string, opts map[string]interface{}, isCA bool, pub crypto.PublicKey) (*x509.Certificate, error) {
+	template := &x509.Certificate{
+		BasicConstraintsValid: true,
+		IsCA:                  isCA,
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		ExtKeyUsage: []x509.ExtKeyUsage{
+			x509.ExtKeyUsageServerAuth,
+			x509.ExtKeyUsageClientAuth,
+		},
+	}
+	if _, ok := pub.(*rsa.PublicKey); ok {
+		template.KeyUsage |= x509.KeyUsageKeyEncipherment
+	}
+
+	if v, ok := opts["isCA"]; ok && !isCA {
+		b, err := cast.ToBoolE(v)
+		if err != nil {
+			return nil, optTypeError(fn, "isCA", v, "bool")
+		}
+		template.IsCA = b
+	}
+	if template.IsCA {
+		template.KeyUsage |= x509.KeyUsageCertSign
+	}
+	if v, ok := opts["maxPathLen"]; ok {
+		n, err := cast.ToIntE(v)
+		if err != nil {
+			return nil, optTypeError(fn, "maxPathLen", v, "int")
+		}
+		template.MaxPathLen = n
+		template.MaxPathLenZero = n == 0
+	}
+
+	if v, ok := opts["keyUsage"]; ok {
+		template.KeyUsage = 0
+		for _, name := range strslice(v) {
+			usage, ok := keyUsageByName(name)
+			if !ok {
+				return nil, &FuncError{Func: fn, Err: fmt.Errorf("unknown key usage %q", name)}
+			}
+			template.KeyUsage |= usage
+		}
+	}
+	if v, ok := opts["extKeyUsage"]; ok {
+		template.ExtKeyUsage = nil
+		for _, name := range strslice(v) {
+			usage, ok := extKeyUsageByName(name)
+			if !ok {
+				return nil, &FuncError{Func: fn, Err: fmt.Errorf("unknown extended key usage %q", name)}
+			}
+			template.ExtKeyUsage = append(template.ExtKeyUsage, usage)
+		}
+	}
+
+	if err := c.setValidity(fn, template, opts); err != nil {
+		return nil, err
+	}
+
+	if v, ok := opts["serialNumber"]; ok {
+		serial, ok := new(big.Int).SetString(strval(v), 0)
+		if !ok || serial.Sign() <= 0 {
+			return nil, &ParseError{Func: fn, Input: strval(v), Err: errors.New("serial number must be a positive integer")}
+		}
+		template.SerialNumber = serial
+	} else {
+		serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+		if err != nil {
+			return nil, &FuncError{Func: fn, Err: err}
+		}
+		template.SerialNumber = serial
+	}
+	return template, nil
+}
+
+// setValidity sets the validity period of a certificate template from the
+// daysValid, notBefore, notAfter and backdate options.
+func (c clock) setValidity(fn string, template *x509.Certificate, opts map[string]interface{}) error {
+	notBefore := c()
+	if v, ok := opts["backdate"]; ok {
+		// Bare numbers are rejected rather than read as nanoseconds.
+		var d time.Duration
+		switch v := v.(type) {
+		case time.Duration:
+			d = v
+		case string:
+			var err error
+			if d, err = time.ParseDuration(v); err != nil {
+				return &ParseError{Func: fn, Input: v, Err: err}
+			}
+		default:
+			return optTypeError(fn, "backdate", v, "duration string")
+		}
+		notBefore = notBefore.Add(-d)
+	}
+	if v, ok := opts["notBefore"]; ok {
+		t, err := optTime(fn, "notBefore", v)
+		if err != nil {
+			return err
+		}
+		notBefore = t
+	}
+
+	daysValid := 365
+	if v, ok := opts["daysValid"]; ok {
+		n, err := cast.ToIntE(v)
+		if err != nil {
+			return optTypeError(fn, "daysValid", v, "int")
+		}
+		daysValid = n
+	}
+	notAfter := notBefore.Add(time.Hour * 24 * time.Duration(daysValid))
+	if v, ok := opts["notAfter"]; ok {
+		t, err := optTime(fn, "notAfter", v)
+		if err != nil {
+			return err
+		}
+		notAfter = t
+	}
+	if !notAfter.After(notBefore) {
+		return &FuncError{Func: fn, Err: errors.New("notAfter must be after notBefore")}
+	}
+
+	template.NotBefore = notBefore
+	template.NotAfter = notAfter
+	return nil
+}
+
+// certKey returns the private key given by the key option, or generates one
+// as described by the keyType and keyBits options.
+func certKey(fn string, opts map[string]interface{}) (crypto.PrivateKey, error) {
+	if v, ok := opts["key"]; ok {
+		priv, err := parsePrivateKeyPEM(strval(v))
+		if err != nil {
+			return nil, &FuncError{Func: fn, Err: fmt.Errorf("parsing private key: %s", err)}
+		}
+		return priv, nil
+	}
+
+	typ := optString(opts, "keyType")
+	if typ == "dsa" {
+		return nil, &FuncError{Func: fn, Err: errors.New("dsa keys cannot be used for certificates")}
+	}
+	bits := 2048
+	if v, ok := opts["keyBits"]; ok {
+		n, err := cast.ToIntE(v)
+		if err != nil {
+			return nil, optTypeError(fn, "keyBits", v, "int")
+		}
+		bits = n
+	}
+	priv, err := generateKey(typ, bits)
+	if err != nil {
+		return nil, &FuncError{Func: fn, Err: err}
+	}
+	return priv, nil
+}
+
+func optString(opts map[string]interface{}, key string) string {
+	if v, ok := opts[key]; ok && v != nil {
+		return strval(v)
+	}
+	return ""
+}
+
+func optStrings(opts map[string]interface{}, key string) []string {
+	if v, ok := opts[key]; ok {
+		return strslice(v)
+	}
+	return nil
+}
+
+func optTime(fn string, key string, v interface{}) (time.Time, error) {
+	switch v.(type) {
+	case time.Time, *time.Time, string:
+		return toTime(fn, v)
+	}
+	return time.Time{}, optTypeError(fn, key, v, "time or date string")
+}
+
+func optTypeError(fn string, key string, v interface{}, want string) error {
+	return &TypeError{Func: fn, Arg: fmt.Sprintf("option %q", key), Got: typeName(v), Want: want}
+}
+
+func keyUsageByName(name string) (x509.KeyUsage, bool) {
+	for _, u := range keyUsages {
+		if u.name == name {
+			return u.usage, true
+		}
+	}
+	return 0, false
+}
+
+func extKeyUsageByName(name string) (x509.ExtKeyUsage, bool) {
+	for usage, n := range extKeyUsages {
+		if n == name {
+			return usage, true
+		}
+	}
+	return 0, false
+}
+
+// genCSR generates a PEM encoded certificate signing request for the private
+// key from a dict of subject options, as accepted by genCAWithOptions,
+// genSelfSignedCertWithOptions and genSignedCertWithOptions.
+func genCSR(opts map[string]interface{}, key string) (string, error) {
+	const fn = "genCSR"
+	if err := checkOptions(fn, opts, subjectOptions); err != nil {
+		return "", err
+	}
+	subject, err := subjectFromOptions(fn, opts)
+	if err != nil {
+		return "", err
+	}
+	priv, err := parsePrivateKeyPEM(key)
+	if err != nil {
+		return "", &FuncError{Func: fn, Err: fmt.Errorf("parsing private key: %s", err)}
+	}
+	der, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+		Subject:        subject.name,
+		DNSNames:       subject.dnsNames,
+		EmailAddresses: subject.emailAddresses,
+		IPAddresses:    subject.ipAddresses,
+		URIs:           subject.uris,
+	}, priv)
+	if err != nil {
+		return "", &FuncError{Func: fn, Err: err}
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: der})), nil
+}
+
+// signCSR signs a PEM encoded certificate signing request with a certificate
+// authority and returns the PEM encoded certificate. The subject and subject
+// alternative names are taken from the request, and the remaining fields
+// from a dict of issuing options, as accepted by genCAWithOptions,
+// genSelfSignedCertWithOptions and genSignedCertWithOptions.
+func (c clock) signCSR(opts map[string]interface{}, ca certificate, csr string) (string, error) {
+	const fn = "signCSR"
+	if err := checkOptions(fn, opts, issueOptions); err != nil {
+		return "", err
+	}
+	block, _ := pem.Decode([]byte(csr))
+	if block == nil || (block.Type != "CERTIFICATE REQUEST" && block.Type != "NEW CERTIFICATE REQUEST") {
+		return "", &ParseError{Func: fn, Input: csr, Err: errors.New("no PEM encoded certificate request found")}
+	}
+	req, err := x509.ParseCertificateRequest(block.Bytes)
+	if err != nil {
+		return "", &ParseError{Func: fn, Input: csr, Err: err}
+	}
+	if err := req.CheckSignature(); err != nil {
+		return "", &FuncError{Func: fn, Err: err}
+	}
+
+	parent, signerKey, err := parseCA(fn, ca)
+	if err != nil {
+		return "", err
+	}
+	template, err := c.certTemplate(fn, opts, false, req.PublicKey)
+	if err != nil {
+		return "", err
+	}
+	template.Subject = req.Subject
+	template.DNSNames = req.DNSNames
+	template.EmailAddresses = req.EmailAddresses
+	template.IPAddresses = req.IPAddresses
+	template.URIs = req.URIs
+
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, req.PublicKey, signerKey)
+	if err != nil {
+		return "", &FuncError{Func: fn, Err: err}
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})), nil
+}
+
+// verifyCertChain reports whether a certificate chains up to one of the
+// given roots, ignoring errors.
+func (c clock) verifyCertChain(opts map[string]interface{}, cert interface{}) bool {
+	ok, _ := c.mustVerifyCertChain(opts, cert)
+	return ok
+}
+
+// mustVerifyCertChain verifies that a certificate chains up to one of the
+// given roots, returning an error describing why it does not. The options
+// are:
+//
+//   - roots: the trusted certificates (required)
+//   - intermediates: certificates that may be used to build the chain
+//   - dnsName: a name the certificate must be valid for
+//   - currentTime: the time at which the chain must be valid, as a time or a
+//     date string, now by default
+//   - extKeyUsage: usages the certificate must allow, any by default
+//
+// Certificates may be given as PEM strings holding one or more certificates,
+// as results of genCA and the like, or as lists of these.
+func (c clock) mustVerifyCertChain(opts map[string]interface{}, cert interface{}) (bool, error) {
+	const fn = "verifyCertChain"
+	if err := checkOptions(fn, opts, map[string]bool{
+		"roots": true, "intermediates": true, "dnsName": true,
+		"currentTime": true, "extKeyUsage": true,
+	}); err != nil {
+		return false, err
+	}
+	leaf, err := parseCertPEM(fn, cert)
+	if err != nil {
+		return false, err
+	}
+	if opts["roots"] == nil {
+		return false, &FuncError{Func: fn, Err: errors.New("no roots given")}
+	}
+	roots, err := certPool(fn, opts["roots"])
+	if err != nil {
+		return false, err
+	}
+	intermediates, err := certPool(fn, opts["intermediates"])
+	if err != nil {
+		return false, err
+	}
+
+	vopts := x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+		DNSName:       optString(opts, "dnsName"),
+		CurrentTime:   c(),
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	}
+	if v, ok := opts["currentTime"]; ok {
+		if vopts.CurrentTime, err = optTime(fn, "currentTime", v); err != nil {
+			return false, err
+		}
+	}
+	if v, ok := opts["extKeyUsage"]; ok {
+		vopts.KeyUsages = nil
+		for _, name := range strslice(v) {
+			usage, ok := extKeyUsageByName(name)
+			if !ok {
+				return false, &FuncError{Func: fn, Err: fmt.Errorf("unknown extended key usage %q", name)}
+			}
+			vopts.KeyUsages = append(vopts.KeyUsages, usage)
+		}
+	}
+
+	if _, err := leaf.Verify(vopts); err != nil {
+		return false, &FuncError{Func: fn, Err: err}
+	}
+	return true, nil
+}
+
+// certPool returns a pool of all certificates in v, which may be a PEM string,
+// a certificate or a list of these.
+func certPool(fn string, v interface{}) (*x509.CertPool, error) {
+	pool := x509.NewCertPool()
+	var add func(v interface{}) error
+	add = func(v interface{}) error {
+		switch v := v.(type) {
+		case nil:
+			return nil
+		case string:
+			if !pool.AppendCertsFromPEM([]byte(v)) {
+				return &ParseError{Func: fn, Input: v, Err: errors.New("no PEM encoded certificate found")}
+			}
+			return nil
+		case certificate:
+			return add(v.Cert)
+		case *certificate:
+			return add(v.Cert)
+		case []string:
+			for _, item := range v {
+				if err := add(item); err != nil {
+					return err
+				}
+			}
+			return nil
+		case []interface{}:
+			for _, item := range v {
+				if err := add(item); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		return &TypeError{Func: fn, Arg: "certificates", Got: typeName(v), Want: "PEM string, certificate or list"}
+	}
+	if err := add(v); err != nil {
+		return nil, err
+	}
+	return pool, nil
+}
@@ -0,0 +1,286 @@
+package sprig
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCert(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	ca, err := clock(func() time.Time { return now }).generateCertificateAuthority("foo-ca", 365)
+	assert.NoError(t, err)
+	cert, err := clock(func() time.Time { return now }).generateSignedCertificate(
+		"foo.com",
+		[]interface{}{"10.0.0.1"},
+		[]interface{}{"foo.com", "www.foo.com"},
+		30,
+		ca,
+	)
+	assert.NoError(t, err)
+
+	d, err := mustParseCert(cert)
+	assert.NoError(t, err)
+	assert.Equal(t, "foo.com", d["subject"].(map[string]interface{})["commonName"])
+	assert.Equal(t, "CN=foo-ca", d["issuer"].(map[string]interface{})["dn"])
+	assert.Equal(t, []string{"foo.com", "www.foo.com"}, d["dnsNames"])
+	assert.Equal(t, []string{"10.0.0.1"}, d["ipAddresses"])
+	assert.Equal(t, []string{}, d["uris"])
+	assert.Equal(t, now, d["notBefore"])
+	assert.Equal(t, now.AddDate(0, 0, 30), d["notAfter"])
+	assert.Equal(t, false, d["isCA"])
+	assert.Equal(t, []string{"digitalSignature", "keyEncipherment"}, d["keyUsage"])
+	assert.Equal(t, []string{"serverAuth", "clientAuth"}, d["extKeyUsage"])
+	assert.Equal(t, "RSA", d["publicKeyAlgorithm"])
+	assert.Len(t, d["sha1Fingerprint"], 40)
+	assert.Len(t, d["sha256Fingerprint"], 64)
+
+	d, err = mustParseCert(ca.Cert)
+	assert.NoError(t, err)
+	assert.Equal(t, true, d["isCA"])
+	assert.Equal(t, []string{"digitalSignature", "keyEncipherment", "certSign"}, d["keyUsage"])
+
+	tpl := `{{ $c := genCA "foo-ca" 365 | parseCert }}{{ $c.subject.commonName }} {{ $c.isCA }}`
+	assert.NoError(t, runt(tpl, "foo-ca true"))
+}
+
+func TestParseCertErrors(t *testing.T) {
+	assert.Equal(t, map[string]interface{}{}, parseCert("not a cert"))
+
+	_, err := mustParseCert("not a cert")
+	var perr *ParseError
+	assert.ErrorAs(t, err, &perr)
+	assert.Equal(t, "parseCert", perr.Func)
+
+	_, err = mustParseCert(42)
+	var terr *TypeError
+	assert.ErrorAs(t, err, &terr)
+	assert.Equal(t, "parseCert: certificate must be PEM string or certificate, got int", err.Error())
+
+	_, err = mustParseCert("-----BEGIN CERTIFICATE-----\nAAAA\n-----END CERTIFICATE-----\n")
+	assert.ErrorAs(t, err, &perr)
+}
+
+func TestGenCertWithOptions(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	c := clock(func() time.Time { return now })
+
+	ca, err := c.genCAWithOptions(map[string]interface{}{
+		"commonName":   "root",
+		"organization": "Acme",
+		"country":      []interface{}{"US"},
+		"keyType":      "ecdsa",
+		"maxPathLen":   1,
+		"serialNumber": "4096",
+		"daysValid":    3650,
+	})
+	assert.NoError(t, err)
+	d, err := mustParseCert(ca)
+	assert.NoError(t, err)
+	assert.Equal(t, "CN=root,O=Acme,C=US", d["subject"].(map[string]interface{})["dn"])
+	assert.Equal(t, true, d["isCA"])
+	assert.Equal(t, "ECDSA", d["publicKeyAlgorithm"])
+	assert.Equal(t, "4096", d["serialNumber"])
+	assert.Equal(t, now.AddDate(0, 0, 3650), d["notAfter"])
+
+	intermediate, err := c.genSignedCertWithOptions(map[string]interface{}{
+		"commonName": "intermediate",
+		"isCA":       true,
+		"maxPathLen": 0,
+		"keyType":    "ed25519",
+		"keyUsage":   []interface{}{"certSign", "crlSign"},
+	}, ca)
+	assert.NoError(t, err)
+	d, err = mustParseCert(intermediate)
+	assert.NoError(t, err)
+	assert.Equal(t, true, d["isCA"])
+	assert.Equal(t, "Ed25519", d["publicKeyAlgorithm"])
+	assert.Equal(t, []string{"certSign", "crlSign"}, d["keyUsage"])
+	assert.Equal(t, "CN=root,O=Acme,C=US", d["issuer"].(map[string]interface{})["dn"])
+
+	leaf, err := c.genSignedCertWithOptions(map[string]interface{}{
+		"commonName":     "svc",
+		"dnsNames":       []string{"svc.example.com"},
+		"ipAddresses":    []interface{}{"10.0.0.1"},
+		"uris":           "spiffe://example.com/svc",
+		"emailAddresses": "ops@example.com",
+		"extKeyUsage":    "serverAuth",
+		"backdate":       "1h",
+		"daysValid":      7,
+	}, intermediate)
+	assert.NoError(t, err)
+	d, err = mustParseCert(leaf)
+	assert.NoError(t, err)
+	assert.Equal(t, false, d["isCA"])
+	assert.Equal(t, []string{"svc.example.com"}, d["dnsNames"])
+	assert.Equal(t, []string{"10.0.0.1"}, d["ipAddresses"])
+	assert.Equal(t, []string{"spiffe://example.com/svc"}, d["uris"])
+	assert.Equal(t, []string{"ops@example.com"}, d["emailAddresses"])
+	assert.Equal(t, []string{"serverAuth"}, d["extKeyUsage"])
+	assert.Equal(t, now.Add(-time.Hour), d["notBefore"])
+	assert.Equal(t, now.Add(-time.Hour).AddDate(0, 0, 7), d["notAfter"])
+
+	self, err := c.genSelfSignedCertWithOptions(map[string]interface{}{
+		"commonName": "self",
+		"notBefore":  "2024-01-01T00:00:00Z",
+		"notAfter":   "2024-02-01T00:00:00Z",
+		"key":        ca.Key,
+	})
+	assert.NoError(t, err)
+	d, err = mustParseCert(self)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), d["notBefore"])
+	assert.Equal(t, time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC), d["notAfter"])
+	assert.Equal(t, "ECDSA", d["publicKeyAlgorithm"])
+
+	// The times accept the same formats as parseDate.
+	self, err = c.genSelfSignedCertWithOptions(map[string]interface{}{
+		"commonName": "self",
+		"notBefore":  "2024-01-01 00:00:00 +0000",
+		"notAfter":   "20240201",
+		"key":        ca.Key,
+	})
+	assert.NoError(t, err)
+	d, err = mustParseCert(self)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), d["notBefore"])
+	assert.True(t, time.Date(2024, 2, 1, 0, 0, 0, 0, time.Local).Equal(d["notAfter"].(time.Time)))
+
+	tpl := `{{ $ca := genCAWithOptions (dict "commonName" "tpl-ca" "keyType" "ecdsa") }}{{ (parseCert $ca).subject.commonName }}`
+	assert.NoError(t, runt(tpl, "tpl-ca"))
+}
+
+func TestGenCertWithOptionsKeyUsage(t *testing.T) {
+	// Key encipherment is only valid for RSA keys.
+	for keyType, want := range map[string][]string{
+		"rsa":     {"digitalSignature", "keyEncipherment"},
+		"ecdsa":   {"digitalSignature"},
+		"ed25519": {"digitalSignature"},
+	} {
+		cert, err := systemClock.genSelfSignedCertWithOptions(map[string]interface{}{"commonName": "x", "keyType": keyType})
+		assert.NoError(t, err)
+		d, err := mustParseCert(cert)
+		assert.NoError(t, err)
+		assert.Equal(t, want, d["keyUsage"], keyType)
+	}
+}
+
+func TestGenCertWithOptionsErrors(t *testing.T) {
+	c := systemClock
+	tests := map[string]map[string]interface{}{
+		`genCAWithOptions: unknown option "comonName"`:                             {"comonName": "x"},
+		`genCAWithOptions: unknown key usage "signing"`:                            {"keyUsage": "signing", "keyType": "ecdsa"},
+		`genCAWithOptions: option "daysValid" must be int, got []string`:           {"daysValid": []string{}},
+		`genCAWithOptions: notAfter must be after notBefore`:                       {"daysValid": 0},
+		`genCAWithOptions: dsa keys cannot be used for certificates`:               {"keyType": "dsa"},
+		`genCAWithOptions: Unknown type rsa2`:                                      {"keyType": "rsa2"},
+		`genCAWithOptions: invalid IP address`:                                     {"ipAddresses": "nope"},
+		`genCAWithOptions: serial number must be a positive integer`:               {"serialNumber": "-1"},
+		`genCAWithOptions: option "backdate" must be duration string, got int`:     {"backdate": 3600, "keyType": "ecdsa"},
+		`genCAWithOptions: time: unknown unit " hour" in duration "1 hour"`:        {"backdate": "1 hour", "keyType": "ecdsa"},
+		`genCAWithOptions: option "notAfter" must be time or date string, got int`: {"notAfter": 1, "keyType": "ecdsa"},
+	}
+	for expect, opts := range tests {
+		_, err := c.genCAWithOptions(opts)
+		assert.EqualError(t, err, expect)
+	}
+}
+
+func TestCSR(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	c := clock(func() time.Time { return now })
+
+	ca, err := c.genCAWithOptions(map[string]interface{}{"commonName": "root", "keyType": "ecdsa"})
+	assert.NoError(t, err)
+
+	key := generatePrivateKey("ecdsa")
+	csr, err := genCSR(map[string]interface{}{
+		"commonName":   "svc",
+		"organization": "Acme",
+		"dnsNames":     []interface{}{"svc.example.com"},
+		"ipAddresses":  "10.0.0.1",
+	}, key)
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(csr, "-----BEGIN CERTIFICATE REQUEST-----"))
+
+	cert, err := c.signCSR(map[string]interface{}{"daysValid": 30, "extKeyUsage": "serverAuth"}, ca, csr)
+	assert.NoError(t, err)
+	d, err := mustParseCert(cert)
+	assert.NoError(t, err)
+	assert.Equal(t, "CN=svc,O=Acme", d["subject"].(map[string]interface{})["dn"])
+	assert.Equal(t, "CN=root", d["issuer"].(map[string]interface{})["dn"])
+	assert.Equal(t, []string{"svc.example.com"}, d["dnsNames"])
+	assert.Equal(t, []string{"10.0.0.1"}, d["ipAddresses"])
+	assert.Equal(t, now.AddDate(0, 0, 30), d["notAfter"])
+	assert.Equal(t, []string{"serverAuth"}, d["extKeyUsage"])
+	assert.Equal(t, []string{"digitalSignature"}, d["keyUsage"])
+
+	_, err = genCSR(map[string]interface{}{"daysValid": 30}, key)
+	assert.EqualError(t, err, `genCSR: unknown option "daysValid"`)
+	_, err = c.signCSR(map[string]interface{}{"commonName": "x"}, ca, csr)
+	assert.EqualError(t, err, `signCSR: unknown option "commonName"`)
+	_, err = c.signCSR(nil, ca, ca.Cert)
+	var perr *ParseError
+	assert.ErrorAs(t, err, &perr)
+}
+
+func TestVerifyCertChain(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	c := clock(func() time.Time { return now })
+
+	root, err := c.genCAWithOptions(map[string]interface{}{"commonName": "root", "keyType": "ecdsa"})
+	assert.NoError(t, err)
+	intermediate, err := c.genSignedCertWithOptions(map[string]interface{}{
+		"commonName": "intermediate", "isCA": true, "keyType": "ecdsa",
+	}, root)
+	assert.NoError(t, err)
+	leaf, err := c.genSignedCertWithOptions(map[string]interface{}{
+		"commonName": "svc", "dnsNames": "svc.example.com", "keyType": "ecdsa", "daysValid": 30,
+	}, intermediate)
+	assert.NoError(t, err)
+	other, err := c.genCAWithOptions(map[string]interface{}{"commonName": "other", "keyType": "ecdsa"})
+	assert.NoError(t, err)
+
+	ok, err := c.mustVerifyCertChain(map[string]interface{}{
+		"roots":         root,
+		"intermediates": []interface{}{intermediate},
+		"dnsName":       "svc.example.com",
+	}, leaf)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	// Several roots in a single PEM string.
+	assert.True(t, c.verifyCertChain(map[string]interface{}{
+		"roots":         other.Cert + root.Cert,
+		"intermediates": intermediate.Cert,
+	}, leaf.Cert))
+
+	tests := []map[string]interface{}{
+		{"roots": root},
+		{"roots": other, "intermediates": intermediate},
+		{"roots": root, "intermediates": intermediate, "dnsName": "other.example.com"},
+		{"roots": root, "intermediates": intermediate, "currentTime": now.AddDate(0, 0, 31)},
+		{"roots": root, "intermediates": intermediate, "extKeyUsage": "codeSigning"},
+	}
+	for _, opts := range tests {
+		ok, err := c.mustVerifyCertChain(opts, leaf)
+		assert.False(t, ok)
+		var ferr *FuncError
+		assert.ErrorAs(t, err, &ferr)
+	}
+
+	_, err = c.mustVerifyCertChain(map[string]interface{}{}, leaf)
+	assert.EqualError(t, err, "verifyCertChain: no roots given")
+	_, err = c.mustVerifyCertChain(map[string]interface{}{"roots": 42}, leaf)
+	assert.EqualError(t, err, "verifyCertChain: certificates must be PEM string, certificate or list, got int")
+
+	fm := New(WithClock(func() time.Time { return now })).TxtFuncMap()
+	tpl := `{{ $ca := genCAWithOptions (dict "commonName" "root" "keyType" "ecdsa") }}` +
+		`{{ $key := genPrivateKey "ecdsa" }}` +
+		`{{ $csr := $key | genCSR (dict "commonName" "svc" "dnsNames" (list "svc.example.com")) }}` +
+		`{{ $cert := $csr | signCSR (dict "daysValid" 30) $ca }}` +
+		`{{ $cert | verifyCertChain (dict "roots" $ca "dnsName" "svc.example.com") }}`
+	assert.NoError(t, runtFuncs(fm, tpl, "true"))
+}
@@ -10,11 +10,13 @@ import (
 	"crypto/ed25519"
 	"crypto/elliptic"
 	"crypto/hmac"
+	"crypto/md5"
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/sha1"
 	"crypto/sha256"
 	"crypto/sha512"
+	"crypto/subtle"
 	"crypto/x509"
 	"crypto/x509/pkix"
 	"encoding/asn1"
@@ -24,7 +26,10 @@ import (
 	"encoding/pem"
 	"errors"
 	"fmt"
+	"hash"
 	"hash/adler32"
+	"hash/crc32"
+	"hash/fnv"
 	"io"
 	"math/big"
 	"net"
@@ -33,8 +38,13 @@ import (
 	"strings"
 
 	"github.com/google/uuid"
+	"golang.org/x/crypto/argon2"
 	bcrypt_lib "golang.org/x/crypto/bcrypt"
+	"golang.org/x/crypto/blake2b"
+	"golang.org/x/crypto/pbkdf2"
 	"golang.org/x/crypto/scrypt"
+	"golang.org/x/crypto/sha3"
+	"golang.org/x/crypto/ssh"
 )
 
 func sha512sum(input string) string {
@@ -57,6 +67,105 @@ func adler32sum(input string) string {
 	return fmt.Sprintf("%d", hash)
 }
 
+func sha384sum(input string) string {
+	hash := sha512.Sum384([]byte(input))
+	return hex.EncodeToString(hash[:])
+}
+
+func sha3_256sum(input string) string {
+	hash := sha3.Sum256([]byte(input))
+	return hex.EncodeToString(hash[:])
+}
+
+// blake2bsum computes the BLAKE2b-512 digest, like b2sum.
+func blake2bsum(input string) string {
+	hash := blake2b.Sum512([]byte(input))
+	return hex.EncodeToString(hash[:])
+}
+
+func md5sum(input string) string {
+	hash := md5.Sum([]byte(input))
+	return hex.EncodeToString(hash[:])
+}
+
+func crc32sum(input string) string {
+	return fmt.Sprintf("%08x", crc32.ChecksumIEEE([]byte(input)))
+}
+
+// fnv64sum computes the 64-bit FNV-1a hash.
+func fnv64sum(input string) string {
+	h := fnv.New64a()
+	h.Write([]byte(input))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// hashAlgorithms are the algorithms accepted by hash and the hmac functions.
+var hashAlgorithms = map[string]func() hash.Hash{
+	"md5":         md5.New,
+	"sha1":        sha1.New,
+	"sha224":      sha256.New224,
+	"sha256":      sha256.New,
+	"sha384":      sha512.New384,
+	"sha512":      sha512.New,
+	"sha3-224":    func() hash.Hash { return sha3.New224() },
+	"sha3-256":    func() hash.Hash { return sha3.New256() },
+	"sha3-384":    func() hash.Hash { return sha3.New384() },
+	"sha3-512":    func() hash.Hash { return sha3.New512() },
+	"blake2b-256": func() hash.Hash { h, _ := blake2b.New256(nil); return h },
+	"blake2b-512": func() hash.Hash { h, _ := blake2b.New512(nil); return h },
+	"crc32":       func() hash.Hash { return crc32.NewIEEE() },
+	"fnv64":       func() hash.Hash { return fnv.New64a() },
+	"adler32":     func() hash.Hash { return adler32.New() },
+}
+
+// hashData computes the hex encoded digest of input with the named algorithm.
+// Every algorithm is hex encoded, including adler32, which adler32sum writes
+// in decimal instead.
+func hashData(algorithm string, input string) (string, error) {
+	newHash, ok := hashAlgorithms[strings.ToLower(algorithm)]
+	if !ok {
+		return "", &FuncError{Func: "hash", Err: fmt.Errorf("unknown hash algorithm %q", algorithm)}
+	}
+	h := newHash()
+	h.Write([]byte(input))
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// hmacSha256 computes the HMAC-SHA256 of data. The optional encoding of the
+// result is hex (the default), base64 or base64url.
+func hmacSha256(key string, data string, encoding ...string) (string, error) {
+	return hmacSum("hmacSha256", sha256.New, key, data, encoding)
+}
+
+// hmacSha512 computes the HMAC-SHA512 of data. The optional encoding of the
+// result is hex (the default), base64 or base64url.
+func hmacSha512(key string, data string, encoding ...string) (string, error) {
+	return hmacSum("hmacSha512", sha512.New, key, data, encoding)
+}
+
+func hmacSum(fn string, newHash func() hash.Hash, key string, data string, encoding []string) (string, error) {
+	enc := "hex"
+	switch len(encoding) {
+	case 0:
+	case 1:
+		enc = encoding[0]
+	default:
+		return "", &FuncError{Func: fn, Err: fmt.Errorf("expected at most one encoding, got %d", len(encoding))}
+	}
+	mac := hmac.New(newHash, []byte(key))
+	mac.Write([]byte(data))
+	sum := mac.Sum(nil)
+	switch enc {
+	case "hex":
+		return hex.EncodeToString(sum), nil
+	case "base64":
+		return base64.StdEncoding.EncodeToString(sum), nil
+	case "base64url":
+		return base64.URLEncoding.EncodeToString(sum), nil
+	}
+	return "", &FuncError{Func: fn, Err: fmt.Errorf("unknown encoding %q", enc)}
+}
+
 func bcrypt(input string) string {
 	hash, err := bcrypt_lib.GenerateFromPassword([]byte(input), bcrypt_lib.DefaultCost)
 	if err != nil {
@@ -94,6 +203,144 @@ func htpasswd(username string, password string, hashAlgorithm HashAlgorithm) str
 	}
 }
 
+// bcryptCost generates the bcrypt hash of a password with the given cost.
+func bcryptCost(cost int, password string) (string, error) {
+	if cost < bcrypt_lib.MinCost || cost > bcrypt_lib.MaxCost {
+		return "", &FuncError{Func: "bcryptCost", Err: fmt.Errorf("cost must be between %d and %d, got %d", bcrypt_lib.MinCost, bcrypt_lib.MaxCost, cost)}
+	}
+	hash, err := bcrypt_lib.GenerateFromPassword([]byte(password), cost)
+	if err != nil {
+		return "", &FuncError{Func: "bcryptCost", Err: err}
+	}
+	return string(hash), nil
+}
+
+// Parameters of the password hashes generated by argon2id and scryptHash.
+const (
+	argon2Time    = 3
+	argon2Memory  = 64 * 1024
+	argon2Threads = 4
+	scryptLogN    = 15
+	scryptR       = 8
+	scryptP       = 1
+
+	passwordSaltSize = 16
+	passwordKeySize  = 32
+)
+
+// argon2id generates an Argon2id hash of a password as a PHC string, such as
+// "$argon2id$v=19$m=65536,t=3,p=4$<salt>$<hash>".
+func argon2id(password string) (string, error) {
+	salt := make([]byte, passwordSaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return "", &FuncError{Func: "argon2id", Err: err}
+	}
+	key := argon2.IDKey([]byte(password), salt, argon2Time, argon2Memory, argon2Threads, passwordKeySize)
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, argon2Memory, argon2Time, argon2Threads,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+// scryptHash generates a scrypt hash of a password as a PHC string, such as
+// "$scrypt$ln=15,r=8,p=1$<salt>$<hash>".
+func scryptHash(password string) (string, error) {
+	salt := make([]byte, passwordSaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return "", &FuncError{Func: "scryptHash", Err: err}
+	}
+	key, err := scrypt.Key([]byte(password), salt, 1<<scryptLogN, scryptR, scryptP, passwordKeySize)
+	if err != nil {
+		return "", &FuncError{Func: "scryptHash", Err: err}
+	}
+	return fmt.Sprintf("$scrypt$ln=%d,r=%d,p=%d$%s$%s",
+		scryptLogN, scryptR, scryptP,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+// verifyPassword reports whether a password matches a hash generated by
+// bcrypt, bcryptCost, argon2id, scryptHash or htpasswd. The hash may be
+// prefixed by a "user:" as in htpasswd files. Unrecognized or malformed
+// hashes do not match.
+func verifyPassword(hash string, password string) bool {
+	if i := strings.Index(hash, ":"); i >= 0 && !strings.HasPrefix(hash, "$") && !strings.HasPrefix(hash, "{") {
+		hash = hash[i+1:]
+	}
+	switch {
+	case strings.HasPrefix(hash, "$2a$"), strings.HasPrefix(hash, "$2b$"), strings.HasPrefix(hash, "$2y$"):
+		return bcrypt_lib.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+	case strings.HasPrefix(hash, "$argon2id$"):
+		return verifyArgon2id(hash, password)
+	case strings.HasPrefix(hash, "$scrypt$"):
+		return verifyScrypt(hash, password)
+	case strings.HasPrefix(hash, "{SHA}"):
+		return subtle.ConstantTimeCompare([]byte(hash[len("{SHA}"):]), []byte(hashSha(password))) == 1
+	}
+	return false
+}
+
+func verifyArgon2id(hash string, password string) bool {
+	// "", "argon2id", "v=19", "m=65536,t=3,p=4", salt, key
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 || parts[2] != fmt.Sprintf("v=%d", argon2.Version) {
+		return false
+	}
+	var m, t uint32
+	var p uint8
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &m, &t, &p); err != nil {
+		return false
+	}
+	// Bound the parameters so that a crafted hash cannot exhaust resources.
+	if t == 0 || t > 16 || p == 0 || m < 8*uint32(p) || m > 1<<20 {
+		return false
+	}
+	salt, key, ok := decodeSaltAndKey(parts[4], parts[5])
+	if !ok {
+		return false
+	}
+	got := argon2.IDKey([]byte(password), salt, t, m, p, uint32(len(key)))
+	return subtle.ConstantTimeCompare(got, key) == 1
+}
+
+func verifyScrypt(hash string, password string) bool {
+	// "", "scrypt", "ln=15,r=8,p=1", salt, key
+	parts := strings.Split(hash, "$")
+	if len(parts) != 5 {
+		return false
+	}
+	var ln, r, p int
+	if _, err := fmt.Sscanf(parts[2], "ln=%d,r=%d,p=%d", &ln, &r, &p); err != nil {
+		return false
+	}
+	if ln < 1 || ln > 20 || r < 1 || r > 32 || p < 1 || p > 16 {
+		return false
+	}
+	salt, key, ok := decodeSaltAndKey(parts[3], parts[4])
+	if !ok {
+		return false
+	}
+	got, err := scrypt.Key([]byte(password), salt, 1<<ln, r, p, len(key))
+	if err != nil {
+		return false
+	}
+	return subtle.ConstantTimeCompare(got, key) == 1
+}
+
+func decodeSaltAndKey(salt64 string, key64 string) ([]byte, []byte, bool) {
+	salt, err := base64.RawStdEncoding.DecodeString(salt64)
+	if err != nil {
+		return nil, nil, false
+	}
+	key, err := base64.RawStdEncoding.DecodeString(key64)
+	if err != nil || len(key) == 0 || len(key) > 128 {
+		return nil, nil, false
+	}
+	return salt, key, true
+}
+
 func randBytes(count int) (string, error) {
 	buf := make([]byte, count)
 	if _, err := rand.Read(buf); err != nil {
@@ -172,17 +419,28 @@ func derivePassword(counter uint32, passwordType, password, user, site string) s
 }
 
 func generatePrivateKey(typ string) string {
-	var priv interface{}
+	priv, err := generateKey(typ, 4096)
+	if err != nil {
+		return err.Error()
+	}
+
+	return string(pem.EncodeToMemory(pemBlockForKey(priv)))
+}
+
+// generateKey generates a private key of the given type. rsaBits is the size
+// of RSA keys.
+func generateKey(typ string, rsaBits int) (crypto.PrivateKey, error) {
+	var priv crypto.PrivateKey
 	var err error
 	switch typ {
 	case "", "rsa":
 		// good enough for government work
-		priv, err = rsa.GenerateKey(rand.Reader, 4096)
+		priv, err = rsa.GenerateKey(rand.Reader, rsaBits)
 	case "dsa":
 		key := new(dsa.PrivateKey)
 		// again, good enough for government work
 		if err = dsa.GenerateParameters(&key.Parameters, rand.Reader, dsa.L2048N256); err != nil {
-			return fmt.Sprintf("failed to generate dsa params: %s", err)
+			return nil, fmt.Errorf("failed to generate dsa params: %s", err)
 		}
 		err = dsa.GenerateKey(key, rand.Reader)
 		priv = key
@@ -192,13 +450,12 @@ func generatePrivateKey(typ string) string {
 	case "ed25519":
 		_, priv, err = ed25519.GenerateKey(rand.Reader)
 	default:
-		return "Unknown type " + typ
+		return nil, errors.New("Unknown type " + typ)
 	}
 	if err != nil {
-		return fmt.Sprintf("failed to generate private key: %s", err)
+		return nil, fmt.Errorf("failed to generate private key: %s", err)
 	}
-
-	return string(pem.EncodeToMemory(pemBlockForKey(priv)))
+	return priv, nil
 }
 
 // DSAKeyFormat stores the format for DSA keys.
@@ -277,6 +534,15 @@ func parsePrivateKeyPEM(pemBlock string) (crypto.PrivateKey, error) {
 			X: k.X,
 		}
 		return priv, nil
+	case "OPENSSH":
+		priv, err := ssh.ParseRawPrivateKey(pem.EncodeToMemory(block))
+		if err != nil {
+			return nil, fmt.Errorf("parsing OpenSSH private key from PEM: %s", err)
+		}
+		if k, ok := priv.(*ed25519.PrivateKey); ok {
+			return *k, nil
+		}
+		return priv, nil
 	default:
 		return nil, fmt.Errorf("invalid private key type %s", block.Type)
 	}
@@ -337,7 +603,7 @@ func buildCustomCertificate(b64cert string, b64key string) (certificate, error)
 	return crt, nil
 }
 
-func generateCertificateAuthority(
+func (c clock) generateCertificateAuthority(
 	cn string,
 	daysValid int,
 ) (certificate, error) {
@@ -346,10 +612,10 @@ func generateCertificateAuthority(
 		return certificate{}, fmt.Errorf("error generating rsa key: %s", err)
 	}
 
-	return generateCertificateAuthorityWithKeyInternal(cn, daysValid, priv)
+	return generateCertificateAuthorityWithKeyInternal(c(), cn, daysValid, priv)
 }
 
-func generateCertificateAuthorityWithPEMKey(
+func (c clock) generateCertificateAuthorityWithPEMKey(
 	cn string,
 	daysValid int,
 	privPEM string,
@@ -358,17 +624,18 @@ func generateCertificateAuthorityWithPEMKey(
 	if err != nil {
 		return certificate{}, fmt.Errorf("parsing private key: %s", err)
 	}
-	return generateCertificateAuthorityWithKeyInternal(cn, daysValid, priv)
+	return generateCertificateAuthorityWithKeyInternal(c(), cn, daysValid, priv)
 }
 
 func generateCertificateAuthorityWithKeyInternal(
+	now time.Time,
 	cn string,
 	daysValid int,
 	priv crypto.PrivateKey,
 ) (certificate, error) {
 	ca := certificate{}
 
-	template, err := getBaseCertTemplate(cn, nil, nil, daysValid)
+	template, err := getBaseCertTemplate(cn, nil, nil, daysValid, now)
 	if err != nil {
 		return ca, err
 	}
@@ -383,7 +650,7 @@ func generateCertificateAuthorityWithKeyInternal(
 	return ca, err
 }
 
-func generateSelfSignedCertificate(
+func (c clock) generateSelfSignedCertificate(
 	cn string,
 	ips []interface{},
 	alternateDNS []interface{},
@@ -393,10 +660,10 @@ func generateSelfSignedCertificate(
 	if err != nil {
 		return certificate{}, fmt.Errorf("error generating rsa key: %s", err)
 	}
-	return generateSelfSignedCertificateWithKeyInternal(cn, ips, alternateDNS, daysValid, priv)
+	return generateSelfSignedCertificateWithKeyInternal(c(), cn, ips, alternateDNS, daysValid, priv)
 }
 
-func generateSelfSignedCertificateWithPEMKey(
+func (c clock) generateSelfSignedCertificateWithPEMKey(
 	cn string,
 	ips []interface{},
 	alternateDNS []interface{},
@@ -407,10 +674,11 @@ func generateSelfSignedCertificateWithPEMKey(
 	if err != nil {
 		return certificate{}, fmt.Errorf("parsing private key: %s", err)
 	}
-	return generateSelfSignedCertificateWithKeyInternal(cn, ips, alternateDNS, daysValid, priv)
+	return generateSelfSignedCertificateWithKeyInternal(c(), cn, ips, alternateDNS, daysValid, priv)
 }
 
 func generateSelfSignedCertificateWithKeyInternal(
+	now time.Time,
 	cn string,
 	ips []interface{},
 	alternateDNS []interface{},
@@ -419,7 +687,7 @@ func generateSelfSignedCertificateWithKeyInternal(
 ) (certificate, error) {
 	cert := certificate{}
 
-	template, err := getBaseCertTemplate(cn, ips, alternateDNS, daysValid)
+	template, err := getBaseCertTemplate(cn, ips, alternateDNS, daysValid, now)
 	if err != nil {
 		return cert, err
 	}
@@ -429,7 +697,7 @@ func generateSelfSignedCertificateWithKeyInternal(
 	return cert, err
 }
 
-func generateSignedCertificate(
+func (c clock) generateSignedCertificate(
 	cn string,
 	ips []interface{},
 	alternateDNS []interface{},
@@ -440,10 +708,10 @@ func generateSignedCertificate(
 	if err != nil {
 		return certificate{}, fmt.Errorf("error generating rsa key: %s", err)
 	}
-	return generateSignedCertificateWithKeyInternal(cn, ips, alternateDNS, daysValid, ca, priv)
+	return generateSignedCertificateWithKeyInternal(c(), cn, ips, alternateDNS, daysValid, ca, priv)
 }
 
-func generateSignedCertificateWithPEMKey(
+func (c clock) generateSignedCertificateWithPEMKey(
 	cn string,
 	ips []interface{},
 	alternateDNS []interface{},
@@ -455,10 +723,11 @@ func generateSignedCertificateWithPEMKey(
 	if err != nil {
 		return certificate{}, fmt.Errorf("parsing private key: %s", err)
 	}
-	return generateSignedCertificateWithKeyInternal(cn, ips, alternateDNS, daysValid, ca, priv)
+	return generateSignedCertificateWithKeyInternal(c(), cn, ips, alternateDNS, daysValid, ca, priv)
 }
 
 func generateSignedCertificateWithKeyInternal(
+	now time.Time,
 	cn string,
 	ips []interface{},
 	alternateDNS []interface{},
@@ -487,7 +756,7 @@ func generateSignedCertificateWithKeyInternal(
 		)
 	}
 
-	template, err := getBaseCertTemplate(cn, ips, alternateDNS, daysValid)
+	template, err := getBaseCertTemplate(cn, ips, alternateDNS, daysValid, now)
 	if err != nil {
 		return cert, err
 	}
@@ -547,6 +816,7 @@ func getBaseCertTemplate(
 	ips []interface{},
 	alternateDNS []interface{},
 	daysValid int,
+	notBefore time.Time,
 ) (*x509.Certificate, error) {
 	ipAddresses, err := getNetIPs(ips)
 	if err != nil {
@@ -568,8 +838,8 @@ func getBaseCertTemplate(
 		},
 		IPAddresses: ipAddresses,
 		DNSNames:    dnsNames,
-		NotBefore:   time.Now(),
-		NotAfter:    time.Now().Add(time.Hour * 24 * time.Duration(daysValid)),
+		NotBefore:   notBefore,
+		NotAfter:    notBefore.Add(time.Hour * 24 * time.Duration(daysValid)),
 		KeyUsage:    x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature,
 		ExtKeyUsage: []x509.ExtKeyUsage{
 			x509.ExtKeyUsageServerAuth,
@@ -678,3 +948,158 @@ func decryptAES(password string, crypt64 string) (string, error) {
 
 	return string(decrypted[:len(decrypted)-int(decrypted[len(decrypted)-1])]), nil
 }
+
+// aesGCMVersion is the version of the envelope written by encryptAESGCM.
+const aesGCMVersion = 1
+
+// Key derivation functions recorded in the AES-GCM envelope.
+const (
+	kdfScrypt   = 1
+	kdfArgon2id = 2
+	kdfPBKDF2   = 3
+)
+
+// The AES-GCM envelope is a base64 encoded header followed by the nonce and
+// the sealed plaintext. The header holds the version, the key derivation
+// function, three KDF parameters and the salt, and is authenticated as
+// additional data.
+const (
+	aesGCMSaltSize   = 16
+	aesGCMHeaderSize = 2 + 3*4 + aesGCMSaltSize
+)
+
+var aesGCMKDFs = map[string]byte{
+	"scrypt":   kdfScrypt,
+	"argon2id": kdfArgon2id,
+	"pbkdf2":   kdfPBKDF2,
+}
+
+// encryptAESGCM encrypts text with AES-256 GCM using a key derived from the
+// password with scrypt.
+func encryptAESGCM(password string, plaintext string) (string, error) {
+	return sealAESGCM("encryptAESGCM", "scrypt", password, plaintext)
+}
+
+// encryptAESGCMWithKDF encrypts text with AES-256 GCM using a key derived from
+// the password with the named key derivation function: scrypt, argon2id or
+// pbkdf2.
+func encryptAESGCMWithKDF(kdf string, password string, plaintext string) (string, error) {
+	return sealAESGCM("encryptAESGCMWithKDF", kdf, password, plaintext)
+}
+
+func sealAESGCM(fn string, kdf string, password string, plaintext string) (string, error) {
+	id, ok := aesGCMKDFs[kdf]
+	if !ok {
+		return "", &FuncError{Func: fn, Err: fmt.Errorf("unknown key derivation function %q", kdf)}
+	}
+
+	var params [3]uint32
+	switch id {
+	case kdfScrypt:
+		params = [3]uint32{32768, 8, 1}
+	case kdfArgon2id:
+		params = [3]uint32{1, 64 * 1024, 4}
+	case kdfPBKDF2:
+		params = [3]uint32{600000, 0, 0}
+	}
+
+	header := make([]byte, aesGCMHeaderSize)
+	header[0] = aesGCMVersion
+	header[1] = id
+	for i, p := range params {
+		binary.BigEndian.PutUint32(header[2+4*i:], p)
+	}
+	salt := header[aesGCMHeaderSize-aesGCMSaltSize:]
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return "", &FuncError{Func: fn, Err: err}
+	}
+
+	aead, err := newAESGCM(id, params, password, salt)
+	if err != nil {
+		return "", &FuncError{Func: fn, Err: err}
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", &FuncError{Func: fn, Err: err}
+	}
+
+	out := append(header, nonce...)
+	out = aead.Seal(out, nonce, []byte(plaintext), header)
+	return base64.StdEncoding.EncodeToString(out), nil
+}
+
+// decryptAESGCM decrypts text encrypted by encryptAESGCM or
+// encryptAESGCMWithKDF. It returns an error if the text was encrypted with a
+// different password or has been modified or truncated.
+func decryptAESGCM(password string, crypt64 string) (string, error) {
+	crypt, err := base64.StdEncoding.DecodeString(crypt64)
+	if err != nil {
+		return "", &ParseError{Func: "decryptAESGCM", Input: crypt64, Err: err}
+	}
+	if len(crypt) < aesGCMHeaderSize {
+		return "", &FuncError{Func: "decryptAESGCM", Err: errors.New("ciphertext is too short")}
+	}
+	if crypt[0] != aesGCMVersion {
+		return "", &FuncError{Func: "decryptAESGCM", Err: fmt.Errorf("unsupported ciphertext version %d", crypt[0])}
+	}
+
+	header := crypt[:aesGCMHeaderSize]
+	var params [3]uint32
+	for i := range params {
+		params[i] = binary.BigEndian.Uint32(header[2+4*i:])
+	}
+	aead, err := newAESGCM(header[1], params, password, header[aesGCMHeaderSize-aesGCMSaltSize:])
+	if err != nil {
+		return "", &FuncError{Func: "decryptAESGCM", Err: err}
+	}
+
+	rest := crypt[aesGCMHeaderSize:]
+	if len(rest) < aead.NonceSize()+aead.Overhead() {
+		return "", &FuncError{Func: "decryptAESGCM", Err: errors.New("ciphertext is too short")}
+	}
+	nonce, sealed := rest[:aead.NonceSize()], rest[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, sealed, header)
+	if err != nil {
+		return "", &FuncError{Func: "decryptAESGCM", Err: errors.New("message authentication failed")}
+	}
+	return string(plaintext), nil
+}
+
+// newAESGCM derives a 256-bit key from the password and returns an AES-GCM
+// cipher using it. The KDF parameters are bounded so that a crafted envelope
+// cannot exhaust memory or CPU.
+func newAESGCM(kdf byte, params [3]uint32, password string, salt []byte) (cipher.AEAD, error) {
+	var key []byte
+	switch kdf {
+	case kdfScrypt:
+		n, r, p := params[0], params[1], params[2]
+		if n < 2 || n > 1<<20 || r == 0 || r > 32 || p == 0 || p > 16 {
+			return nil, fmt.Errorf("invalid scrypt parameters N=%d r=%d p=%d", n, r, p)
+		}
+		var err error
+		key, err = scrypt.Key([]byte(password), salt, int(n), int(r), int(p), 32)
+		if err != nil {
+			return nil, err
+		}
+	case kdfArgon2id:
+		t, m, p := params[0], params[1], params[2]
+		if t == 0 || t > 16 || m < 8*p || m > 1<<20 || p == 0 || p > 255 {
+			return nil, fmt.Errorf("invalid argon2id parameters t=%d m=%d p=%d", t, m, p)
+		}
+		key = argon2.IDKey([]byte(password), salt, t, m, uint8(p), 32)
+	case kdfPBKDF2:
+		iter := params[0]
+		if iter == 0 || iter > 10000000 {
+			return nil, fmt.Errorf("invalid pbkdf2 iterations %d", iter)
+		}
+		key = pbkdf2.Key([]byte(password), salt, int(iter), 32, sha256.New)
+	default:
+		return nil, fmt.Errorf("unknown key derivation function %d", kdf)
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
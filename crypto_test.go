@@ -3,8 +3,10 @@ package sprig
 import (
 	"crypto/x509"
 	"encoding/base64"
+	"encoding/binary"
 	"encoding/pem"
 	"fmt"
+	"strconv"
 	"strings"
 	"testing"
 
@@ -54,6 +56,58 @@ func TestAdler32Sum(t *testing.T) {
 	}
 }
 
+func TestHashSums(t *testing.T) {
+	tests := map[string]string{
+		`{{"abc" | sha384sum}}`:   "cb00753f45a35e8bb5a03d699ac65007272c32ab0eded1631a8b605a43ff5bed8086072ba1e7cc2358baeca134c825a7",
+		`{{"abc" | sha3_256sum}}`: "3a985da74fe225b2045c172d6bd390bd855f086e3e9d525b46bfe24511431532",
+		`{{"abc" | blake2bsum}}`:  "ba80a53f981c4d0d6a2797b69f12f6e94c212f14685ac4b74b12bb6fdbffa2d17d87c5392aab792dc252d5de4533cc9518d38aa8dbf1925ab92386edd4009923",
+		`{{"abc" | md5sum}}`:      "900150983cd24fb0d6963f7d28e17f72",
+		`{{"abc" | crc32sum}}`:    "352441c2",
+		`{{"abc" | fnv64sum}}`:    "e71fa2190541574b",
+
+		`{{"abc" | hash "sha256"}}`:      "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad",
+		`{{"abc" | hash "SHA3-256"}}`:    "3a985da74fe225b2045c172d6bd390bd855f086e3e9d525b46bfe24511431532",
+		`{{"abc" | hash "blake2b-256"}}`: "bddd813c634239723171ef3fee98579b94964e3bb1cb3e427262c8c068d52319",
+		`{{"abc" | hash "adler32"}}`:     "024d0127",
+	}
+	for tpl, expect := range tests {
+		if err := runt(tpl, expect); err != nil {
+			t.Error(err)
+		}
+	}
+
+	// hash writes adler32 in hex, while adler32sum writes the same checksum in
+	// decimal.
+	sum, err := strconv.ParseUint(adler32sum("abc"), 10, 32)
+	assert.NoError(t, err)
+	hexSum, err := hashData("adler32", "abc")
+	assert.NoError(t, err)
+	assert.Equal(t, fmt.Sprintf("%08x", sum), hexSum)
+
+	_, err = runRaw(`{{"abc" | hash "sha0"}}`, nil)
+	assert.ErrorContains(t, err, `hash: unknown hash algorithm "sha0"`)
+}
+
+func TestHmac(t *testing.T) {
+	const msg = "The quick brown fox jumps over the lazy dog"
+	tests := map[string]string{
+		`{{ . | hmacSha256 "key" }}`:           "f7bc83f430538424b13298e6aa6fb143ef4d59a14946175997479dbc2d1a3cd8",
+		`{{ hmacSha256 "key" . "base64" }}`:    "97yD9DBThCSxMpjmqm+xQ+9NWaFJRhdZl0edvC0aPNg=",
+		`{{ hmacSha256 "key" . "base64url" }}`: "97yD9DBThCSxMpjmqm-xQ-9NWaFJRhdZl0edvC0aPNg=",
+		`{{ . | hmacSha512 "key" }}`:           "b42af09057bac1e2d41708e48a902e09b5ff7f12ab428a4fe86653c73dd248fb82f948a549f7b791a5b41915ee4d1ec3935357e4e2317250d0372afa2ebeeb3a",
+	}
+	for tpl, expect := range tests {
+		if err := runtv(tpl, expect, msg); err != nil {
+			t.Error(err)
+		}
+	}
+
+	_, err := hmacSha256("key", msg, "base32")
+	assert.EqualError(t, err, `hmacSha256: unknown encoding "base32"`)
+	_, err = hmacSha512("key", msg, "hex", "hex")
+	assert.EqualError(t, err, "hmacSha512: expected at most one encoding, got 2")
+}
+
 func TestBcrypt(t *testing.T) {
 	out, err := runRaw(`{{"abc" | bcrypt}}`, nil)
 	if err != nil {
@@ -64,6 +118,46 @@ func TestBcrypt(t *testing.T) {
 	}
 }
 
+func TestBcryptCost(t *testing.T) {
+	out, err := runRaw(`{{"abc" | bcryptCost 5}}`, nil)
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(out, "$2a$05$"))
+	assert.True(t, verifyPassword(out, "abc"))
+
+	_, err = bcryptCost(3, "abc")
+	assert.EqualError(t, err, "bcryptCost: cost must be between 4 and 31, got 3")
+}
+
+func TestPasswordHashes(t *testing.T) {
+	argon, err := argon2id("secret")
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(argon, "$argon2id$v=19$m=65536,t=3,p=4$"))
+	assert.True(t, verifyPassword(argon, "secret"))
+	assert.False(t, verifyPassword(argon, "Secret"))
+
+	scryptOut, err := scryptHash("secret")
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(scryptOut, "$scrypt$ln=15,r=8,p=1$"))
+	assert.True(t, verifyPassword(scryptOut, "secret"))
+	assert.False(t, verifyPassword(scryptOut, "Secret"))
+
+	// Generated with Python's hashlib.scrypt.
+	const external = "$scrypt$ln=10,r=8,p=1$MDEyMzQ1Njc4OWFiY2RlZg$S7FwBvpu+z8K0PUVUagFRTUAB2dAxIZpzVHvLZir+98"
+	assert.True(t, verifyPassword(external, "secret"))
+
+	assert.True(t, verifyPassword("{SHA}W6ph5Mm5Pz8GgiULbPgzG37mj9g=", "password"))
+	assert.True(t, verifyPassword(htpasswd("user", "password", HashSHA), "password"))
+	assert.True(t, verifyPassword(htpasswd("user", "password", HashBCrypt), "password"))
+	assert.False(t, verifyPassword(htpasswd("user", "password", HashBCrypt), "wrong"))
+
+	assert.False(t, verifyPassword("plaintext", "plaintext"))
+	assert.False(t, verifyPassword("$argon2id$v=19$m=4294967295,t=1,p=1$c2FsdA$a2V5", "secret"))
+	assert.False(t, verifyPassword("$scrypt$ln=30,r=8,p=1$c2FsdA$a2V5", "secret"))
+
+	tpl := `{{ $h := "secret" | argon2id }}{{ "secret" | verifyPassword $h }}`
+	assert.NoError(t, runt(tpl, "true"))
+}
+
 type HtpasswdCred struct {
 	Username      string
 	Password      string
@@ -233,7 +327,7 @@ func TestUUIDGeneration(t *testing.T) {
 }
 
 func TestBuildCustomCert(t *testing.T) {
-	ca, _ := generateCertificateAuthority("example.com", 365)
+	ca, _ := systemClock.generateCertificateAuthority("example.com", 365)
 	tpl := fmt.Sprintf(
 		`{{- $ca := buildCustomCert "%s" "%s"}}
 {{- $ca.Cert }}`,
@@ -440,3 +534,55 @@ func TestEncryptDecryptAES(t *testing.T) {
 		t.Error(err)
 	}
 }
+
+func TestEncryptDecryptAESGCM(t *testing.T) {
+	tpl := `{{"plaintext" | encryptAESGCM "secretkey" | decryptAESGCM "secretkey"}}`
+	if err := runt(tpl, "plaintext"); err != nil {
+		t.Error(err)
+	}
+
+	for _, kdf := range []string{"scrypt", "argon2id", "pbkdf2"} {
+		tpl := fmt.Sprintf(`{{"plaintext" | encryptAESGCMWithKDF %q "secretkey" | decryptAESGCM "secretkey"}}`, kdf)
+		if err := runt(tpl, "plaintext"); err != nil {
+			t.Error(err)
+		}
+	}
+
+	_, err := runRaw(`{{"plaintext" | encryptAESGCMWithKDF "md5" "secretkey"}}`, nil)
+	assert.Error(t, err)
+}
+
+func TestDecryptAESGCMErrors(t *testing.T) {
+	crypt, err := encryptAESGCM("secretkey", "plaintext")
+	assert.NoError(t, err)
+	raw, err := base64.StdEncoding.DecodeString(crypt)
+	assert.NoError(t, err)
+
+	_, err = decryptAESGCM("wrongkey", crypt)
+	assert.EqualError(t, err, "decryptAESGCM: message authentication failed")
+
+	tampered := append([]byte{}, raw...)
+	tampered[len(tampered)-1] ^= 1
+	_, err = decryptAESGCM("secretkey", base64.StdEncoding.EncodeToString(tampered))
+	assert.EqualError(t, err, "decryptAESGCM: message authentication failed")
+
+	_, err = decryptAESGCM("secretkey", base64.StdEncoding.EncodeToString(raw[:aesGCMHeaderSize+4]))
+	assert.EqualError(t, err, "decryptAESGCM: ciphertext is too short")
+
+	_, err = decryptAESGCM("secretkey", "")
+	assert.EqualError(t, err, "decryptAESGCM: ciphertext is too short")
+
+	badVersion := append([]byte{}, raw...)
+	badVersion[0] = 9
+	_, err = decryptAESGCM("secretkey", base64.StdEncoding.EncodeToString(badVersion))
+	assert.EqualError(t, err, "decryptAESGCM: unsupported ciphertext version 9")
+
+	huge := append([]byte{}, raw...)
+	binary.BigEndian.PutUint32(huge[2:], 1<<30)
+	_, err = decryptAESGCM("secretkey", base64.StdEncoding.EncodeToString(huge))
+	assert.EqualError(t, err, "decryptAESGCM: invalid scrypt parameters N=1073741824 r=8 p=1")
+
+	_, err = decryptAESGCM("secretkey", "not base64!")
+	var perr *ParseError
+	assert.ErrorAs(t, err, &perr)
+}
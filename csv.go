@@ -0,0 +1,308 @@
+package sprig
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// fromCsv decodes CSV with a header row into a list of dicts, ignoring errors.
+func fromCsv(v string) []interface{} {
+	output, _ := decodeDelimited("fromCsv", ",", v)
+	return output
+}
+
+// mustFromCsv decodes CSV with a header row into a list of dicts, returning errors.
+func mustFromCsv(v string) ([]interface{}, error) {
+	return decodeDelimited("fromCsv", ",", v)
+}
+
+// fromCsvRows decodes CSV into a list of lists, ignoring errors.
+func fromCsvRows(v string) []interface{} {
+	output, _ := decodeDelimitedRows("fromCsvRows", ",", v)
+	return output
+}
+
+// mustFromCsvRows decodes CSV into a list of lists, returning errors.
+func mustFromCsvRows(v string) ([]interface{}, error) {
+	return decodeDelimitedRows("fromCsvRows", ",", v)
+}
+
+// fromTsv decodes TSV with a header row into a list of dicts, ignoring errors.
+func fromTsv(v string) []interface{} {
+	output, _ := decodeDelimited("fromTsv", "\t", v)
+	return output
+}
+
+// mustFromTsv decodes TSV with a header row into a list of dicts, returning errors.
+func mustFromTsv(v string) ([]interface{}, error) {
+	return decodeDelimited("fromTsv", "\t", v)
+}
+
+// fromTsvRows decodes TSV into a list of lists, ignoring errors.
+func fromTsvRows(v string) []interface{} {
+	output, _ := decodeDelimitedRows("fromTsvRows", "\t", v)
+	return output
+}
+
+// mustFromTsvRows decodes TSV into a list of lists, returning errors.
+func mustFromTsvRows(v string) ([]interface{}, error) {
+	return decodeDelimitedRows("fromTsvRows", "\t", v)
+}
+
+// fromDelimited decodes delimiter-separated values with a header row into a
+// list of dicts, ignoring errors.
+func fromDelimited(sep string, v string) []interface{} {
+	output, _ := decodeDelimited("fromDelimited", sep, v)
+	return output
+}
+
+// mustFromDelimited decodes delimiter-separated values with a header row into
+// a list of dicts keyed by the header, returning errors.
+func mustFromDelimited(sep string, v string) ([]interface{}, error) {
+	return decodeDelimited("fromDelimited", sep, v)
+}
+
+func decodeDelimited(fn string, sep string, v string) ([]interface{}, error) {
+	records, err := readDelimited(fn, sep, v)
+	if err != nil {
+		return nil, err
+	}
+	output := []interface{}{}
+	if len(records) == 0 {
+		return output, nil
+	}
+	header := records[0]
+	seen := make(map[string]bool, len(header))
+	for _, key := range header {
+		if seen[key] {
+			return nil, &ParseError{Func: fn, Input: v, Err: fmt.Errorf("duplicate column %q in header", key)}
+		}
+		seen[key] = true
+	}
+	for _, record := range records[1:] {
+		row := make(map[string]interface{}, len(header))
+		for i, key := range header {
+			row[key] = record[i]
+		}
+		output = append(output, row)
+	}
+	return output, nil
+}
+
+// fromDelimitedRows decodes delimiter-separated values into a list of lists,
+// ignoring errors.
+func fromDelimitedRows(sep string, v string) []interface{} {
+	output, _ := decodeDelimitedRows("fromDelimitedRows", sep, v)
+	return output
+}
+
+// mustFromDelimitedRows decodes delimiter-separated values into a list of
+// lists, returning errors.
+func mustFromDelimitedRows(sep string, v string) ([]interface{}, error) {
+	return decodeDelimitedRows("fromDelimitedRows", sep, v)
+}
+
+func decodeDelimitedRows(fn string, sep string, v string) ([]interface{}, error) {
+	records, err := readDelimited(fn, sep, v)
+	if err != nil {
+		return nil, err
+	}
+	output := make([]interface{}, len(records))
+	for i, record := range records {
+		row := make([]interface{}, len(record))
+		for j, field := range record {
+			row[j] = field
+		}
+		output[i] = row
+	}
+	return output, nil
+}
+
+func readDelimited(fn string, sep string, v string) ([][]string, error) {
+	comma, err := delimiter(fn, sep)
+	if err != nil {
+		return nil, err
+	}
+	r := csv.NewReader(strings.NewReader(v))
+	r.Comma = comma
+	records, err := r.ReadAll()
+	if err != nil {
+		return nil, &ParseError{Func: fn, Input: v, Err: err}
+	}
+	return records, nil
+}
+
+// toCsv encodes a list of dicts or lists as CSV, ignoring errors.
+func toCsv(columns interface{}, rows interface{}) string {
+	output, _ := encodeDelimited("toCsv", ",", columns, rows)
+	return output
+}
+
+// mustToCsv encodes a list of dicts or lists as CSV, returning errors.
+func mustToCsv(columns interface{}, rows interface{}) (string, error) {
+	return encodeDelimited("toCsv", ",", columns, rows)
+}
+
+// toTsv encodes a list of dicts or lists as TSV, ignoring errors.
+func toTsv(columns interface{}, rows interface{}) string {
+	output, _ := encodeDelimited("toTsv", "\t", columns, rows)
+	return output
+}
+
+// mustToTsv encodes a list of dicts or lists as TSV, returning errors.
+func mustToTsv(columns interface{}, rows interface{}) (string, error) {
+	return encodeDelimited("toTsv", "\t", columns, rows)
+}
+
+// toDelimited encodes a list of dicts or lists as delimiter-separated values,
+// ignoring errors.
+func toDelimited(sep string, columns interface{}, rows interface{}) string {
+	output, _ := encodeDelimited("toDelimited", sep, columns, rows)
+	return output
+}
+
+// mustToDelimited encodes a list of dicts or lists as delimiter-separated
+// values, returning errors.
+//
+// The columns are written as the header row, and select the values written
+// for each dict. If no columns are given for a list of dicts, the sorted keys
+// of all dicts are used. Lists are written as they are, with no header row
+// unless columns are given. Every row must be a dict, which may be any map with
+// string keys, or every row must be a list.
+func mustToDelimited(sep string, columns interface{}, rows interface{}) (string, error) {
+	return encodeDelimited("toDelimited", sep, columns, rows)
+}
+
+func encodeDelimited(fn string, sep string, columns interface{}, rows interface{}) (string, error) {
+	comma, err := delimiter(fn, sep)
+	if err != nil {
+		return "", err
+	}
+
+	rv := reflect.ValueOf(rows)
+	if rows != nil && rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return "", &TypeError{Func: fn, Arg: "rows", Got: typeName(rows), Want: "slice or array"}
+	}
+
+	// Every row must have the same shape: either all dicts or all lists.
+	var dicts []map[string]interface{}
+	var lists [][]interface{}
+	for i := 0; rows != nil && i < rv.Len(); i++ {
+		item := rv.Index(i).Interface()
+		if dict, ok := stringMap(item); ok && lists == nil {
+			dicts = append(dicts, dict)
+			continue
+		}
+		if list, ok := listValues(item); ok && dicts == nil {
+			lists = append(lists, list)
+			continue
+		}
+		want := "dict or list"
+		if dicts != nil {
+			want = "dict like row 0"
+		} else if lists != nil {
+			want = "list like row 0"
+		}
+		return "", &TypeError{Func: fn, Arg: fmt.Sprintf("row %d", i), Got: typeName(item), Want: want}
+	}
+
+	header := strslice(columns)
+	if len(header) == 0 {
+		header = dictKeys(dicts)
+	}
+
+	buf := new(bytes.Buffer)
+	w := csv.NewWriter(buf)
+	w.Comma = comma
+	if len(header) > 0 {
+		if err := w.Write(header); err != nil {
+			return "", &FuncError{Func: fn, Err: err}
+		}
+	}
+	for _, dict := range dicts {
+		record := make([]string, len(header))
+		for j, key := range header {
+			if val, ok := dict[key]; ok && val != nil {
+				record[j] = strval(val)
+			}
+		}
+		if err := w.Write(record); err != nil {
+			return "", &FuncError{Func: fn, Err: err}
+		}
+	}
+	for _, list := range lists {
+		record := make([]string, len(list))
+		for j, val := range list {
+			if val != nil {
+				record[j] = strval(val)
+			}
+		}
+		if err := w.Write(record); err != nil {
+			return "", &FuncError{Func: fn, Err: err}
+		}
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return "", &FuncError{Func: fn, Err: err}
+	}
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
+
+// dictKeys returns the sorted keys of all dicts.
+func dictKeys(dicts []map[string]interface{}) []string {
+	seen := map[string]bool{}
+	k := []string{}
+	for _, dict := range dicts {
+		for key := range dict {
+			if !seen[key] {
+				seen[key] = true
+				k = append(k, key)
+			}
+		}
+	}
+	sort.Strings(k)
+	return k
+}
+
+// stringMap converts any map with string keys to a dict.
+func stringMap(v interface{}) (map[string]interface{}, bool) {
+	if dict, ok := v.(map[string]interface{}); ok {
+		return dict, true
+	}
+	mv := reflect.ValueOf(v)
+	if v == nil || mv.Kind() != reflect.Map || mv.Type().Key().Kind() != reflect.String {
+		return nil, false
+	}
+	dict := make(map[string]interface{}, mv.Len())
+	iter := mv.MapRange()
+	for iter.Next() {
+		dict[iter.Key().String()] = iter.Value().Interface()
+	}
+	return dict, true
+}
+
+// listValues returns the elements of a slice or array.
+func listValues(v interface{}) ([]interface{}, bool) {
+	lv := reflect.ValueOf(v)
+	if v == nil || (lv.Kind() != reflect.Slice && lv.Kind() != reflect.Array) {
+		return nil, false
+	}
+	list := make([]interface{}, lv.Len())
+	for i := range list {
+		list[i] = lv.Index(i).Interface()
+	}
+	return list, true
+}
+
+func delimiter(fn string, sep string) (rune, error) {
+	r, size := utf8.DecodeRuneInString(sep)
+	if size == 0 || size != len(sep) {
+		return 0, &FuncError{Func: fn, Err: fmt.Errorf("delimiter must be a single character, got %q", sep)}
+	}
+	return r, nil
+}
@@ -0,0 +1,70 @@
+package sprig
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFromCsv(t *testing.T) {
+	vars := map[string]interface{}{"Input": "name,age\nalice,30\nbob,25\n"}
+
+	assert.NoError(t, runtv(`{{ range .Input | fromCsv }}{{ .name }}={{ .age }};{{ end }}`, "alice=30;bob=25;", vars))
+	assert.NoError(t, runtv(`{{ .Input | fromCsv | first | keys | sortAlpha }}`, "[age name]", vars))
+	assert.NoError(t, runtv(`{{ .Input | fromCsvRows | first }}`, "[name age]", vars))
+	assert.NoError(t, runtv(`{{ .Input | fromCsvRows | len }}`, "3", vars))
+	assert.NoError(t, runt(`{{ "" | fromCsv | len }}`, "0"))
+	assert.NoError(t, runt(`{{ "a,b\n1" | fromCsv | len }}`, "0"))
+	assert.Error(t, runt(`{{ "a,b\n1" | mustFromCsv }}`, ""))
+}
+
+func TestFromTsv(t *testing.T) {
+	vars := map[string]interface{}{"Input": "name\tage\nalice\t30\n"}
+
+	assert.NoError(t, runtv(`{{ range .Input | fromTsv }}{{ .name }}={{ .age }}{{ end }}`, "alice=30", vars))
+	assert.NoError(t, runtv(`{{ .Input | fromTsvRows | last }}`, "[alice 30]", vars))
+	assert.NoError(t, runt(`{{ range "a;b\n1;2" | fromDelimited ";" }}{{ .b }}{{ end }}`, "2"))
+	assert.NoError(t, runt(`{{ "a;b\n1;2" | fromDelimitedRows ";" | last }}`, "[1 2]"))
+	assert.Error(t, runt(`{{ "a;b" | mustFromDelimited ";;" }}`, ""))
+
+	_, err := runRaw(`{{ "a;b" | mustFromDelimited ";;" }}`, nil)
+	assert.ErrorContains(t, err, `fromDelimited: delimiter must be a single character, got ";;"`)
+
+	_, err = runRaw(`{{ "a,b,a\n1,2,3" | mustFromCsv }}`, nil)
+	var perr *ParseError
+	if assert.ErrorAs(t, err, &perr) {
+		assert.Equal(t, "fromCsv", perr.Func)
+		assert.ErrorContains(t, err, `duplicate column "a" in header`)
+	}
+	assert.NoError(t, runt(`{{ "a,b,a\n1,2,3" | fromCsvRows | last }}`, "[1 2 3]"))
+}
+
+func TestToCsv(t *testing.T) {
+	vars := map[string]interface{}{
+		"Dicts": []interface{}{
+			map[string]interface{}{"name": "alice", "age": 30},
+			map[string]interface{}{"name": "bob, jr", "email": "bob@example.com"},
+		},
+		"Lists": [][]string{{"a", "b"}, {"c", "d"}},
+		"Typed": []map[string]string{{"name": "alice"}, {"name": "bob"}},
+		"Mixed": []interface{}{map[string]interface{}{"name": "alice"}, []string{"bob"}},
+	}
+
+	assert.NoError(t, runtv(`{{ .Dicts | toCsv (list "name" "age") }}`, "name,age\nalice,30\n\"bob, jr\",", vars))
+	assert.NoError(t, runtv(`{{ .Dicts | toCsv list }}`, "age,email,name\n30,,alice\n,bob@example.com,\"bob, jr\"", vars))
+	assert.NoError(t, runtv(`{{ .Lists | toCsv list }}`, "a,b\nc,d", vars))
+	assert.NoError(t, runtv(`{{ .Lists | toCsv (list "x" "y") }}`, "x,y\na,b\nc,d", vars))
+	assert.NoError(t, runtv(`{{ .Lists | toTsv list }}`, "a\tb\nc\td", vars))
+	assert.NoError(t, runtv(`{{ .Lists | toDelimited "|" list }}`, "a|b\nc|d", vars))
+	assert.NoError(t, runtv(`{{ .Input | fromCsv | toCsv (list "name" "age") }}`, "name,age\nalice,30", map[string]interface{}{"Input": "name,age\nalice,30"}))
+	assert.Error(t, runt(`{{ "nope" | mustToCsv list }}`, ""))
+	assert.Error(t, runt(`{{ list 1 2 | mustToCsv list }}`, ""))
+	assert.NoError(t, runtv(`{{ .Typed | toCsv list }}`, "name\nalice\nbob", vars))
+
+	_, err := runRaw(`{{ .Mixed | mustToCsv list }}`, vars)
+	var terr *TypeError
+	if assert.ErrorAs(t, err, &terr) {
+		assert.Equal(t, "row 1", terr.Arg)
+		assert.Equal(t, "dict like row 0", terr.Want)
+	}
+}
@@ -1,44 +1,69 @@
 package sprig
 
 import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
 	"strconv"
+	"strings"
 	"time"
 )
 
+// clock returns the current time. Functions that depend on the current time
+// are methods on clock so that function maps can be built with a fixed or
+// custom clock.
+type clock func() time.Time
+
+// systemClock reads the current time from the system.
+var systemClock clock = time.Now
+
 // Given a format and a date, format the date string.
 //
-// Date can be a `time.Time` or an `int, int32, int64`.
-// In the later case, it is treated as seconds since UNIX
-// epoch.
-func date(fmt string, date interface{}) string {
-	return dateInZone(fmt, date, "Local")
+// Date can be anything parseDate accepts. If it cannot be converted, the
+// current time is used instead.
+func (c clock) date(fmt string, date interface{}) string {
+	return c.dateInZone(fmt, date, "Local")
 }
 
-func htmlDate(date interface{}) string {
-	return dateInZone("2006-01-02", date, "Local")
+// mustDate is like date, but returns an error if the date cannot be
+// converted.
+func (c clock) mustDate(fmt string, date interface{}) (string, error) {
+	return c.mustDateInZone(fmt, date, "Local")
 }
 
-func htmlDateInZone(date interface{}, zone string) string {
-	return dateInZone("2006-01-02", date, zone)
+func (c clock) htmlDate(date interface{}) string {
+	return c.dateInZone("2006-01-02", date, "Local")
 }
 
-func dateInZone(fmt string, date interface{}, zone string) string {
-	var t time.Time
-	switch date := date.(type) {
-	default:
-		t = time.Now()
-	case time.Time:
-		t = date
-	case *time.Time:
-		t = *date
-	case int64:
-		t = time.Unix(date, 0)
-	case int:
-		t = time.Unix(int64(date), 0)
-	case int32:
-		t = time.Unix(int64(date), 0)
+func (c clock) mustHtmlDate(date interface{}) (string, error) {
+	return c.mustDateInZone("2006-01-02", date, "Local")
+}
+
+func (c clock) htmlDateInZone(date interface{}, zone string) string {
+	return c.dateInZone("2006-01-02", date, zone)
+}
+
+func (c clock) mustHtmlDateInZone(date interface{}, zone string) (string, error) {
+	return c.mustDateInZone("2006-01-02", date, zone)
+}
+
+func (c clock) dateInZone(fmt string, date interface{}, zone string) string {
+	return formatInZone(fmt, c.timeOrNow(date), zone)
+}
+
+func (c clock) mustDateInZone(fmt string, date interface{}, zone string) (string, error) {
+	t, err := toTime("dateInZone", date)
+	if err != nil {
+		return "", err
 	}
+	return formatInZone(fmt, t, zone), nil
+}
 
+// formatInZone formats t in the named location, falling back to UTC if the
+// location is unknown.
+func formatInZone(fmt string, t time.Time, zone string) string {
 	loc, err := time.LoadLocation(zone)
 	if err != nil {
 		loc, _ = time.LoadLocation("UTC")
@@ -47,40 +72,44 @@ func dateInZone(fmt string, date interface{}, zone string) string {
 	return t.In(loc).Format(fmt)
 }
 
-func dateModify(fmt string, date time.Time) time.Time {
+// dateModify adds a duration to a date. The date is returned unmodified if
+// the duration cannot be parsed, and the zero time if the date cannot be
+// converted.
+func dateModify(fmt string, date interface{}) time.Time {
+	t, _ := toTime("dateModify", date)
 	d, err := time.ParseDuration(fmt)
 	if err != nil {
-		return date
+		return t
 	}
-	return date.Add(d)
+	return t.Add(d)
 }
 
-func mustDateModify(fmt string, date time.Time) (time.Time, error) {
-	d, err := time.ParseDuration(fmt)
+func mustDateModify(fmt string, date interface{}) (time.Time, error) {
+	t, err := toTime("dateModify", date)
 	if err != nil {
 		return time.Time{}, err
 	}
-	return date.Add(d), nil
+	d, err := time.ParseDuration(fmt)
+	if err != nil {
+		return time.Time{}, &ParseError{Func: "dateModify", Input: fmt, Err: err}
+	}
+	return t.Add(d), nil
 }
 
-func dateAgo(date interface{}) string {
-	var t time.Time
-
-	switch date := date.(type) {
-	default:
-		t = time.Now()
-	case time.Time:
-		t = date
-	case int64:
-		t = time.Unix(date, 0)
-	case int:
-		t = time.Unix(int64(date), 0)
-	}
+func (c clock) dateAgo(date interface{}) string {
 	// Drop resolution to seconds
-	duration := time.Since(t).Round(time.Second)
+	duration := c().Sub(c.timeOrNow(date)).Round(time.Second)
 	return duration.String()
 }
 
+func (c clock) mustDateAgo(date interface{}) (string, error) {
+	t, err := toTime("ago", date)
+	if err != nil {
+		return "", err
+	}
+	return c().Sub(t).Round(time.Second).String(), nil
+}
+
 func duration(sec interface{}) string {
 	var n int64
 	switch value := sec.(type) {
@@ -94,17 +123,36 @@ func duration(sec interface{}) string {
 	return (time.Duration(n) * time.Second).String()
 }
 
-func durationRound(duration interface{}) string {
+// durationRound rounds a duration to its most significant unit, such as "2h"
+// or "3mo". A time.Duration, a number of nanoseconds or a duration string is
+// used as it is; a time.Time, or a string that is not a duration but is a
+// date parseDate accepts, gives the duration since that time. Anything else
+// is an error.
+func (c clock) durationRound(duration interface{}) (string, error) {
+	const fn = "durationRound"
 	var d time.Duration
-	switch duration := duration.(type) {
-	default:
-		d = 0
-	case string:
-		d, _ = time.ParseDuration(duration)
+	switch v := duration.(type) {
+	case time.Duration:
+		d = v
 	case int64:
-		d = time.Duration(duration)
-	case time.Time:
-		d = time.Since(duration)
+		d = time.Duration(v)
+	case string:
+		var err error
+		if d, err = time.ParseDuration(v); err != nil {
+			t, err := parseDateString(fn, v)
+			if err != nil {
+				return "", &ParseError{Func: fn, Input: v, Err: errors.New("not a duration or a recognized date")}
+			}
+			d = c().Sub(t)
+		}
+	case time.Time, *time.Time:
+		t, err := toTime(fn, v)
+		if err != nil {
+			return "", err
+		}
+		d = c().Sub(t)
+	default:
+		return "", &TypeError{Func: fn, Arg: "duration", Got: typeName(duration), Want: "a duration, duration string or date"}
 	}
 
 	u := uint64(d)
@@ -123,19 +171,19 @@ func durationRound(duration interface{}) string {
 	)
 	switch {
 	case u > year:
-		return strconv.FormatUint(u/year, 10) + "y"
+		return strconv.FormatUint(u/year, 10) + "y", nil
 	case u > month:
-		return strconv.FormatUint(u/month, 10) + "mo"
+		return strconv.FormatUint(u/month, 10) + "mo", nil
 	case u > day:
-		return strconv.FormatUint(u/day, 10) + "d"
+		return strconv.FormatUint(u/day, 10) + "d", nil
 	case u > hour:
-		return strconv.FormatUint(u/hour, 10) + "h"
+		return strconv.FormatUint(u/hour, 10) + "h", nil
 	case u > minute:
-		return strconv.FormatUint(u/minute, 10) + "m"
+		return strconv.FormatUint(u/minute, 10) + "m", nil
 	case u > second:
-		return strconv.FormatUint(u/second, 10) + "s"
+		return strconv.FormatUint(u/second, 10) + "s", nil
 	}
-	return "0s"
+	return "0s", nil
 }
 
 func toDate(fmt, str string) time.Time {
@@ -144,9 +192,853 @@ func toDate(fmt, str string) time.Time {
 }
 
 func mustToDate(fmt, str string) (time.Time, error) {
-	return time.ParseInLocation(fmt, str, time.Local)
+	t, err := time.ParseInLocation(fmt, str, time.Local)
+	if err != nil {
+		return time.Time{}, &ParseError{Func: "toDate", Input: str, Err: err}
+	}
+	return t, nil
+}
+
+func unixEpoch(date interface{}) (string, error) {
+	t, err := toTime("unixEpoch", date)
+	if err != nil {
+		return "", err
+	}
+	return strconv.FormatInt(t.Unix(), 10), nil
+}
+
+// parseDate converts a date given in any of the forms listed in dateLayouts,
+// or as seconds since the Unix epoch, or as a string holding seconds,
+// milliseconds, microseconds or nanoseconds since the Unix epoch, ignoring
+// errors. It returns the zero time if the date cannot be
+// converted.
+func parseDate(date interface{}) time.Time {
+	t, _ := toTime("parseDate", date)
+	return t
+}
+
+// mustParseDate is like parseDate, but returns an error if the date cannot be
+// converted.
+func mustParseDate(date interface{}) (time.Time, error) {
+	return toTime("parseDate", date)
+}
+
+// dateLayouts are the layouts tried in order when parsing a date string.
+// Layouts without a time zone are parsed in the local time zone. Fractional
+// seconds are accepted after the seconds field of every layout.
+var dateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05Z0700",
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04Z07:00",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05Z07:00",
+	"2006-01-02 15:04:05 -0700",
+	"2006-01-02 15:04:05 -0700 MST",
+	"2006-01-02 15:04:05 MST",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+	"20060102T150405Z0700",
+	time.RFC1123Z,
+	time.RFC1123,
+	time.RFC850,
+	time.RFC822Z,
+	time.RFC822,
+	time.UnixDate,
+	time.RubyDate,
+	time.ANSIC,
+	// Common Log Format, as written by Apache and nginx.
+	"02/Jan/2006:15:04:05 -0700",
+	// The standard log package.
+	"2006/01/02 15:04:05",
+}
+
+// epochNumber matches a string holding a number of seconds, milliseconds,
+// microseconds or nanoseconds since the Unix epoch.
+var epochNumber = regexp.MustCompile(`^[-+]?[0-9]+(\.[0-9]+)?$`)
+
+// toTime converts a date to a time.Time. A date can be a time.Time or a
+// pointer to one, a number of seconds since the Unix epoch, or a string
+// holding a date in one of the dateLayouts or a number of seconds,
+// milliseconds, microseconds or nanoseconds since the Unix epoch.
+func toTime(fn string, date interface{}) (time.Time, error) {
+	switch date := date.(type) {
+	case time.Time:
+		return date, nil
+	case *time.Time:
+		if date != nil {
+			return *date, nil
+		}
+	case string:
+		return parseDateString(fn, date)
+	}
+
+	v := reflect.ValueOf(date)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return time.Unix(v.Int(), 0), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if v.Uint() <= math.MaxInt64 {
+			return time.Unix(int64(v.Uint()), 0), nil
+		}
+	case reflect.Float32, reflect.Float64:
+		f := v.Float()
+		if !math.IsNaN(f) && !math.IsInf(f, 0) {
+			sec, frac := math.Modf(f)
+			return time.Unix(int64(sec), int64(frac*1e9)), nil
+		}
+	}
+	return time.Time{}, &TypeError{Func: fn, Arg: "date", Got: typeName(date), Want: "a time, number or date string"}
+}
+
+// parseDateString parses a date string with the first matching layout in
+// dateLayouts, or as a number since the Unix epoch. Eight digits are an ISO
+// 8601 basic date such as 20240107 if they form a valid one.
+func parseDateString(fn string, s string) (time.Time, error) {
+	str := strings.TrimSpace(s)
+	if len(str) == 8 {
+		if t, err := time.ParseInLocation("20060102", str, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	if epochNumber.MatchString(str) {
+		if n, err := strconv.ParseInt(str, 10, 64); err == nil {
+			return fromEpoch(float64(n), n), nil
+		}
+		f, err := strconv.ParseFloat(str, 64)
+		if err != nil {
+			return time.Time{}, &ParseError{Func: fn, Input: s, Err: err}
+		}
+		return fromEpoch(f, int64(f)), nil
+	}
+	for _, layout := range dateLayouts {
+		if t, err := time.ParseInLocation(layout, str, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, &ParseError{Func: fn, Input: s, Err: errors.New("unrecognized date format")}
+}
+
+// fromEpoch returns the time n units after the Unix epoch, guessing the unit
+// from the magnitude: seconds up to 1e11 (the year 5138), then milliseconds,
+// microseconds and nanoseconds. f is n as a float, and carries the fraction
+// of a number of seconds; i is n as an integer, which is exact for the
+// smaller units.
+func fromEpoch(f float64, i int64) time.Time {
+	switch abs := math.Abs(f); {
+	case abs < 1e11:
+		sec, frac := math.Modf(f)
+		return time.Unix(int64(sec), int64(frac*1e9))
+	case abs < 1e14:
+		return time.UnixMilli(i)
+	case abs < 1e17:
+		return time.UnixMicro(i)
+	}
+	return time.Unix(0, i)
+}
+
+// timeOrNow converts a date like toTime, returning the current time if it
+// cannot be converted.
+func (c clock) timeOrNow(date interface{}) time.Time {
+	t, err := toTime("", date)
+	if err != nil {
+		return c()
+	}
+	return t
 }
 
-func unixEpoch(date time.Time) string {
-	return strconv.FormatInt(date.Unix(), 10)
+// dateAdd adds calendar units to a date, ignoring errors. The date is returned
+// unmodified if the modification cannot be parsed, and the zero time if the
+// date cannot be converted.
+func dateAdd(modification string, date interface{}) time.Time {
+	t, _ := toTime("dateAdd", date)
+	if r, err := addCalendar(t, modification); err == nil {
+		return r
+	}
+	return t
+}
+
+// mustDateAdd is like dateAdd, but returns errors.
+func mustDateAdd(modification string, date interface{}) (time.Time, error) {
+	t, err := toTime("dateAdd", date)
+	if err != nil {
+		return time.Time{}, err
+	}
+	r, err := addCalendar(t, modification)
+	if err != nil {
+		return time.Time{}, &ParseError{Func: "dateAdd", Input: modification, Err: err}
+	}
+	return r, nil
+}
+
+// calendarTerm matches one term of a dateAdd modification, such as "+1 month"
+// or "2d".
+var calendarTerm = regexp.MustCompile(`^([-+]?)\s*([0-9]+)\s*([a-zA-Z]+)`)
+
+// addCalendar applies a modification made of terms such as "+1 year",
+// "-2 months 3 days" or "1w", or of "next" or "last" followed by a weekday.
+// A sign applies to the terms that follow it until the next sign.
+func addCalendar(t time.Time, modification string) (time.Time, error) {
+	s := strings.TrimSpace(modification)
+	if fields := strings.Fields(strings.ToLower(s)); len(fields) == 2 && (fields[0] == "next" || fields[0] == "last") {
+		wd, ok := weekdays[fields[1]]
+		if !ok {
+			return t, fmt.Errorf("unknown weekday %q", fields[1])
+		}
+		if fields[0] == "next" {
+			return t.AddDate(0, 0, (int(wd)-int(t.Weekday())+6)%7+1), nil
+		}
+		return t.AddDate(0, 0, -((int(t.Weekday())-int(wd)+6)%7 + 1)), nil
+	}
+	if s == "" {
+		return t, errors.New("empty modification")
+	}
+
+	var years, months, days int
+	var d time.Duration
+	sign := 1
+	for s != "" {
+		m := calendarTerm.FindStringSubmatch(s)
+		if m == nil {
+			return t, fmt.Errorf("cannot parse %q", s)
+		}
+		s = strings.TrimLeft(s[len(m[0]):], " ,")
+		switch m[1] {
+		case "-":
+			sign = -1
+		case "+":
+			sign = 1
+		}
+		n, err := strconv.Atoi(m[2])
+		if err != nil {
+			return t, err
+		}
+		n *= sign
+		switch strings.ToLower(m[3]) {
+		case "y", "year", "years":
+			years += n
+		case "mo", "month", "months":
+			months += n
+		case "w", "week", "weeks":
+			days += 7 * n
+		case "d", "day", "days":
+			days += n
+		case "h", "hour", "hours":
+			d += time.Duration(n) * time.Hour
+		case "m", "min", "mins", "minute", "minutes":
+			d += time.Duration(n) * time.Minute
+		case "s", "sec", "secs", "second", "seconds":
+			d += time.Duration(n) * time.Second
+		default:
+			return t, fmt.Errorf("unknown unit %q", m[3])
+		}
+	}
+	return addMonths(t, 12*years+months).AddDate(0, 0, days).Add(d), nil
+}
+
+// addMonths adds months to t, moving to the last day of the resulting month
+// if t's day of the month does not exist in it.
+func addMonths(t time.Time, months int) time.Time {
+	if months == 0 {
+		return t
+	}
+	y, m, d := t.Date()
+	first := time.Date(y, m+time.Month(months), 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+	if last := first.AddDate(0, 1, -1).Day(); d > last {
+		d = last
+	}
+	return first.AddDate(0, 0, d-1)
+}
+
+// weekdays maps lower case weekday names and abbreviations to weekdays.
+var weekdays = func() map[string]time.Weekday {
+	m := map[string]time.Weekday{}
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		name := strings.ToLower(d.String())
+		m[name] = d
+		m[name[:3]] = d
+	}
+	return m
+}()
+
+// startOf returns the start of the day, week, month, quarter or year, or of
+// the hour or minute, containing a date. Weeks start on Monday. The period is
+// taken in the optional time zone, or else in the date's own time zone.
+func startOf(unit string, date interface{}, zone ...string) (time.Time, error) {
+	t, err := periodDate("startOf", date, zone)
+	if err != nil {
+		return time.Time{}, err
+	}
+	start, _, err := period(t, unit)
+	if err != nil {
+		return time.Time{}, &FuncError{Func: "startOf", Err: err}
+	}
+	return start, nil
+}
+
+// endOf returns the last nanosecond of the period containing a date, with the
+// same periods and time zone handling as startOf.
+func endOf(unit string, date interface{}, zone ...string) (time.Time, error) {
+	t, err := periodDate("endOf", date, zone)
+	if err != nil {
+		return time.Time{}, err
+	}
+	_, next, err := period(t, unit)
+	if err != nil {
+		return time.Time{}, &FuncError{Func: "endOf", Err: err}
+	}
+	return next.Add(-time.Nanosecond), nil
+}
+
+// periodDate converts a date and moves it to the optional time zone.
+func periodDate(fn string, date interface{}, zone []string) (time.Time, error) {
+	t, err := toTime(fn, date)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if len(zone) > 1 {
+		return time.Time{}, &FuncError{Func: fn, Err: errors.New("at most one time zone can be given")}
+	}
+	if len(zone) == 1 {
+		loc, err := time.LoadLocation(zone[0])
+		if err != nil {
+			return time.Time{}, &FuncError{Func: fn, Err: err}
+		}
+		t = t.In(loc)
+	}
+	return t, nil
+}
+
+// period returns the start of the period containing t and the start of the
+// following one.
+func period(t time.Time, unit string) (time.Time, time.Time, error) {
+	y, m, d := t.Date()
+	loc := t.Location()
+	switch strings.ToLower(unit) {
+	case "minute":
+		start := time.Date(y, m, d, t.Hour(), t.Minute(), 0, 0, loc)
+		return start, start.Add(time.Minute), nil
+	case "hour":
+		start := time.Date(y, m, d, t.Hour(), 0, 0, 0, loc)
+		return start, start.Add(time.Hour), nil
+	case "day":
+		return time.Date(y, m, d, 0, 0, 0, 0, loc), time.Date(y, m, d+1, 0, 0, 0, 0, loc), nil
+	case "week":
+		d -= (int(t.Weekday()) + 6) % 7
+		return time.Date(y, m, d, 0, 0, 0, 0, loc), time.Date(y, m, d+7, 0, 0, 0, 0, loc), nil
+	case "month":
+		return time.Date(y, m, 1, 0, 0, 0, 0, loc), time.Date(y, m+1, 1, 0, 0, 0, 0, loc), nil
+	case "quarter":
+		m -= (m - 1) % 3
+		return time.Date(y, m, 1, 0, 0, 0, 0, loc), time.Date(y, m+3, 1, 0, 0, 0, 0, loc), nil
+	case "year":
+		return time.Date(y, 1, 1, 0, 0, 0, 0, loc), time.Date(y+1, 1, 1, 0, 0, 0, 0, loc), nil
+	}
+	return time.Time{}, time.Time{}, fmt.Errorf("unknown period %q", unit)
+}
+
+// dateTruncate rounds a date down to a multiple of a duration such as "15m"
+// or "1h" since the zero time. Use startOf to truncate to a calendar period.
+func dateTruncate(duration string, date interface{}) (time.Time, error) {
+	t, err := toTime("dateTruncate", date)
+	if err != nil {
+		return time.Time{}, err
+	}
+	d, err := time.ParseDuration(duration)
+	if err != nil {
+		return time.Time{}, &ParseError{Func: "dateTruncate", Input: duration, Err: err}
+	}
+	return t.Truncate(d), nil
+}
+
+// weekday returns the English name of the day of the week of a date.
+func weekday(date interface{}) (string, error) {
+	t, err := toTime("weekday", date)
+	if err != nil {
+		return "", err
+	}
+	return t.Weekday().String(), nil
+}
+
+// isoWeek returns the ISO 8601 week number of a date, from 1 to 53.
+func isoWeek(date interface{}) (int, error) {
+	t, err := toTime("isoWeek", date)
+	if err != nil {
+		return 0, err
+	}
+	_, week := t.ISOWeek()
+	return week, nil
+}
+
+// dayOfYear returns the day of the year of a date, from 1 to 366.
+func dayOfYear(date interface{}) (int, error) {
+	t, err := toTime("dayOfYear", date)
+	if err != nil {
+		return 0, err
+	}
+	return t.YearDay(), nil
+}
+
+// daysBetween returns the number of calendar days from one date to another,
+// each taken in its own time zone. It is negative if to is before from.
+func daysBetween(from interface{}, to interface{}) (int, error) {
+	f, err := toTime("daysBetween", from)
+	if err != nil {
+		return 0, err
+	}
+	t, err := toTime("daysBetween", to)
+	if err != nil {
+		return 0, err
+	}
+	civil := func(t time.Time) time.Time {
+		y, m, d := t.Date()
+		return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	}
+	return int(civil(t).Sub(civil(f)).Hours() / 24), nil
+}
+
+// cronField describes one field of a cron expression.
+type cronField struct {
+	name     string
+	min, max int
+	names    []string
+}
+
+var (
+	cronSecond = cronField{name: "second", min: 0, max: 59}
+	cronMinute = cronField{name: "minute", min: 0, max: 59}
+	cronHour   = cronField{name: "hour", min: 0, max: 23}
+	cronDom    = cronField{name: "day of the month", min: 1, max: 31}
+	cronMonth  = cronField{name: "month", min: 1, max: 12, names: []string{
+		"", "january", "february", "march", "april", "may", "june", "july",
+		"august", "september", "october", "november", "december",
+	}}
+	// Both 0 and 7 are Sunday.
+	cronDow = cronField{name: "day of the week", min: 0, max: 7, names: []string{
+		"sunday", "monday", "tuesday", "wednesday", "thursday", "friday", "saturday", "sunday",
+	}}
+)
+
+// cronMacros are the predefined schedules.
+var cronMacros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// cronYears is how far cronNext and cronPrev search for a matching time.
+const cronYears = 400
+
+// cronMaxCount is the most times cronNext and cronPrev return at once.
+const cronMaxCount = 1000
+
+// cronSchedule is a parsed cron expression.
+type cronSchedule struct {
+	// fields are the second, minute, hour, day of the month, month and day
+	// of the week fields, as text and as bit sets of the matching values.
+	fields [6]string
+	bits   [6]uint64
+	// seconds is true if the expression has a seconds field.
+	seconds bool
+	// domStar and dowStar are true if the day fields start with * or ?, in
+	// which case a day only has to match the other day field.
+	domStar, dowStar bool
+	// loc is the time zone given with a CRON_TZ= or TZ= prefix, if any.
+	loc *time.Location
+}
+
+var cronFields = [6]cronField{cronSecond, cronMinute, cronHour, cronDom, cronMonth, cronDow}
+
+// parseCron parses a cron expression with five fields (minute, hour, day of
+// the month, month and day of the week) or six fields (with seconds first),
+// or one of the cronMacros, optionally preceded by CRON_TZ= or TZ= and a time
+// zone name.
+func parseCron(fn string, expr string) (*cronSchedule, error) {
+	s := &cronSchedule{}
+	fail := func(err error) (*cronSchedule, error) {
+		return nil, &ParseError{Func: fn, Input: expr, Err: err}
+	}
+
+	fields := strings.Fields(expr)
+	if len(fields) > 0 {
+		for _, prefix := range []string{"CRON_TZ=", "TZ="} {
+			if name := strings.TrimPrefix(fields[0], prefix); name != fields[0] {
+				loc, err := time.LoadLocation(name)
+				if err != nil {
+					return fail(err)
+				}
+				s.loc = loc
+				fields = fields[1:]
+				break
+			}
+		}
+	}
+	if len(fields) == 1 && strings.HasPrefix(fields[0], "@") {
+		macro, ok := cronMacros[strings.ToLower(fields[0])]
+		if !ok {
+			return fail(fmt.Errorf("unknown macro %s", fields[0]))
+		}
+		fields = strings.Fields(macro)
+	}
+	switch len(fields) {
+	case 5:
+		fields = append([]string{"0"}, fields...)
+	case 6:
+		s.seconds = true
+	default:
+		return fail(fmt.Errorf("expected 5 or 6 fields, got %d", len(fields)))
+	}
+
+	for i, f := range cronFields {
+		bits, err := f.parse(fields[i])
+		if err != nil {
+			return fail(err)
+		}
+		s.fields[i] = fields[i]
+		s.bits[i] = bits
+	}
+	if s.bits[5]&(1<<7) != 0 {
+		s.bits[5] = s.bits[5]&^(1<<7) | 1
+	}
+	s.domStar = fields[3][0] == '*' || fields[3][0] == '?'
+	s.dowStar = fields[5][0] == '*' || fields[5][0] == '?'
+	return s, nil
+}
+
+// parse returns the bit set of the values matched by the text of a field: a
+// comma separated list of *, values and ranges, each optionally followed by
+// a step.
+func (f cronField) parse(text string) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(text, ",") {
+		rng, step := part, 1
+		if i := strings.IndexByte(part, '/'); i >= 0 {
+			n, err := strconv.Atoi(part[i+1:])
+			if err != nil || n < 1 {
+				return 0, fmt.Errorf("invalid step in %s field %q", f.name, text)
+			}
+			rng, step = part[:i], n
+		}
+
+		lo, hi := f.min, f.max
+		switch {
+		case rng == "*" || rng == "?" && (f.name == cronDom.name || f.name == cronDow.name):
+		case strings.Contains(rng, "-"):
+			i := strings.IndexByte(rng, '-')
+			var err error
+			if lo, err = f.value(rng[:i]); err != nil {
+				return 0, err
+			}
+			if hi, err = f.value(rng[i+1:]); err != nil {
+				return 0, err
+			}
+			if lo > hi {
+				return 0, fmt.Errorf("invalid range in %s field %q", f.name, text)
+			}
+		default:
+			var err error
+			if lo, err = f.value(rng); err != nil {
+				return 0, err
+			}
+			if step == 1 {
+				hi = lo
+			}
+		}
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+// value parses a number or name in a field.
+func (f cronField) value(s string) (int, error) {
+	for i, name := range f.names {
+		if name != "" && strings.EqualFold(s, name[:3]) {
+			return i, nil
+		}
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < f.min || n > f.max {
+		return 0, fmt.Errorf("invalid %s %q", f.name, s)
+	}
+	return n, nil
+}
+
+// matchDay reports whether the schedule fires on a day.
+func (s *cronSchedule) matchDay(t time.Time) bool {
+	dom := s.bits[3]&(1<<uint(t.Day())) != 0
+	dow := s.bits[5]&(1<<uint(t.Weekday())) != 0
+	if s.domStar || s.dowStar {
+		return dom && dow
+	}
+	return dom || dow
+}
+
+// next returns the first time the schedule fires after t, or before t if
+// backward is true. It reports false if there is no such time within
+// cronYears.
+func (s *cronSchedule) next(t time.Time, backward bool) (time.Time, bool) {
+	if s.loc != nil {
+		t = t.In(s.loc)
+	}
+	loc := t.Location()
+	dir := 1
+	if backward {
+		dir = -1
+	}
+	// values returns the values of a field in search order.
+	values := func(bits uint64, lo, hi int) []int {
+		var vs []int
+		for v := lo; v <= hi; v++ {
+			if bits&(1<<uint(v)) != 0 {
+				vs = append(vs, v)
+			}
+		}
+		if backward {
+			for i, j := 0, len(vs)-1; i < j; i, j = i+1, j-1 {
+				vs[i], vs[j] = vs[j], vs[i]
+			}
+		}
+		return vs
+	}
+	// skip reports whether v comes before the corresponding part of t in
+	// search order, given that the more significant parts are equal.
+	skip := func(same bool, v, tv int) bool {
+		return same && dir*(v-tv) < 0
+	}
+
+	seconds, minutes, hours := values(s.bits[0], 0, 59), values(s.bits[1], 0, 59), values(s.bits[2], 0, 23)
+	months := values(s.bits[4], 1, 12)
+	for i := 0; i <= cronYears; i++ {
+		y := t.Year() + dir*i
+		for _, m := range months {
+			sameMonth := i == 0 && m == int(t.Month())
+			if skip(i == 0, m, int(t.Month())) {
+				continue
+			}
+			days := time.Date(y, time.Month(m)+1, 0, 0, 0, 0, 0, time.UTC).Day()
+			for _, d := range values(^uint64(0), 1, days) {
+				sameDay := sameMonth && d == t.Day()
+				if skip(sameMonth, d, t.Day()) || !s.matchDay(time.Date(y, time.Month(m), d, 0, 0, 0, 0, time.UTC)) {
+					continue
+				}
+				for _, h := range hours {
+					sameHour := sameDay && h == t.Hour()
+					if skip(sameDay, h, t.Hour()) {
+						continue
+					}
+					for _, mi := range minutes {
+						if skip(sameHour, mi, t.Minute()) {
+							continue
+						}
+						for _, sec := range seconds {
+							c := time.Date(y, time.Month(m), d, h, mi, sec, 0, loc)
+							// Skip wall clock times that do not exist
+							// because of a daylight saving time change.
+							if c.Day() != d || c.Hour() != h || c.Minute() != mi {
+								continue
+							}
+							if (!backward && c.After(t)) || (backward && c.Before(t)) {
+								return c, true
+							}
+						}
+					}
+				}
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
+// cronValid reports whether a cron expression can be parsed.
+func cronValid(expr string) bool {
+	_, err := parseCron("cronValid", expr)
+	return err == nil
+}
+
+// cronNext returns the next count times a cron expression fires after a
+// date, or after the current time if no date is given. The times are in the
+// time zone given in the expression, or else in the date's time zone.
+func (c clock) cronNext(expr string, count int, after ...interface{}) ([]time.Time, error) {
+	return c.cronTimes("cronNext", expr, count, after, false)
+}
+
+// cronPrev is like cronNext, but returns the last count times a cron
+// expression fired before a date, most recent first.
+func (c clock) cronPrev(expr string, count int, before ...interface{}) ([]time.Time, error) {
+	return c.cronTimes("cronPrev", expr, count, before, true)
+}
+
+func (c clock) cronTimes(fn string, expr string, count int, date []interface{}, backward bool) ([]time.Time, error) {
+	s, err := parseCron(fn, expr)
+	if err != nil {
+		return []time.Time{}, err
+	}
+	if count < 0 {
+		return []time.Time{}, &FuncError{Func: fn, Err: fmt.Errorf("count must not be negative, got %d", count)}
+	}
+	if count > cronMaxCount {
+		return []time.Time{}, &FuncError{Func: fn, Err: fmt.Errorf("count must be at most %d, got %d", cronMaxCount, count)}
+	}
+	var t time.Time
+	switch len(date) {
+	case 0:
+		t = c()
+	case 1:
+		if t, err = toTime(fn, date[0]); err != nil {
+			return []time.Time{}, err
+		}
+	default:
+		return []time.Time{}, &FuncError{Func: fn, Err: errors.New("at most one date can be given")}
+	}
+
+	times := make([]time.Time, 0, count)
+	for len(times) < count {
+		var ok bool
+		if t, ok = s.next(t, backward); !ok {
+			return []time.Time{}, &FuncError{Func: fn, Err: fmt.Errorf("%q does not fire within %d years", expr, cronYears)}
+		}
+		times = append(times, t)
+	}
+	return times, nil
+}
+
+// cronDescribe returns an English description of a cron expression, such as
+// "At 03:30, on Monday through Friday".
+func cronDescribe(expr string) (string, error) {
+	s, err := parseCron("cronDescribe", expr)
+	if err != nil {
+		return "", err
+	}
+	sec, min, hour, dom, month, dow := s.fields[0], s.fields[1], s.fields[2], s.fields[3], s.fields[4], s.fields[5]
+
+	var phrases []string
+	if atoms := strings.Split(hour, ","); isCronValue(sec) && isCronValue(min) && allCronValues(atoms) {
+		// A fixed time of day.
+		times := make([]string, len(atoms))
+		for i, h := range atoms {
+			times[i] = fmt.Sprintf("%02d:%02d", cronValue(cronHour, h), cronValue(cronMinute, min))
+			if s.seconds && sec != "0" {
+				times[i] += fmt.Sprintf(":%02d", cronValue(cronSecond, sec))
+			}
+		}
+		phrases = append(phrases, "at "+joinList(times))
+	} else {
+		if s.seconds && sec != "0" {
+			phrases = append(phrases, describeCronField(cronSecond, sec, "at second", "at seconds", ""))
+		}
+		p := describeCronField(cronMinute, min, "at minute", "at minutes", "")
+		if hour == "*" && min != "*" && !strings.HasPrefix(min, "*/") {
+			p += " of every hour"
+		}
+		phrases = append(phrases, p)
+		if hour != "*" {
+			phrases = append(phrases, describeCronField(cronHour, hour, "during hour", "during hours", ""))
+		}
+	}
+	if dom != "*" && dom != "?" {
+		phrases = append(phrases, describeCronField(cronDom, dom, "on day", "on days", " of the month"))
+	}
+	if dow != "*" && dow != "?" {
+		p := describeCronField(cronDow, dow, "on", "on", "")
+		if !s.domStar && !s.dowStar {
+			p = "or " + p
+		}
+		phrases = append(phrases, p)
+	}
+	if month != "*" {
+		phrases = append(phrases, describeCronField(cronMonth, month, "in", "in", ""))
+	}
+	if s.loc != nil {
+		phrases = append(phrases, s.loc.String()+" time")
+	}
+
+	desc := strings.Join(phrases, ", ")
+	return strings.ToUpper(desc[:1]) + desc[1:], nil
+}
+
+// describeCronField describes a field with one value or a list using the
+// given prefixes, and a field with a step or a range on its own.
+func describeCronField(f cronField, text, one, many, suffix string) string {
+	unit := f.name
+	if i := strings.Index(unit, " of"); i >= 0 {
+		unit = unit[:i]
+	}
+	atoms := strings.Split(text, ",")
+	if len(atoms) == 1 {
+		rng, step := text, ""
+		if i := strings.IndexByte(text, '/'); i >= 0 {
+			rng, step = text[:i], text[i+1:]
+		}
+		every := "every " + f.name
+		if step != "" {
+			every = "every " + step + " " + unit + "s"
+		}
+		switch {
+		case rng == "*" || rng == "?":
+			return every
+		case step != "" && strings.Contains(rng, "-"):
+			i := strings.IndexByte(rng, '-')
+			return every + " from " + cronName(f, rng[:i]) + " through " + cronName(f, rng[i+1:])
+		case step != "":
+			return every + " starting at " + cronName(f, rng)
+		}
+	}
+	items := make([]string, len(atoms))
+	for i, a := range atoms {
+		if j := strings.IndexByte(a, '-'); j >= 0 && !strings.Contains(a, "/") {
+			items[i] = cronName(f, a[:j]) + " through " + cronName(f, a[j+1:])
+		} else {
+			items[i] = cronName(f, a)
+		}
+	}
+	prefix := one
+	if len(atoms) > 1 || strings.Contains(text, "-") {
+		prefix = many
+	}
+	return prefix + " " + joinList(items) + suffix
+}
+
+// cronName returns the name of a value in a field with names, or the value.
+func cronName(f cronField, s string) string {
+	v, err := f.value(s)
+	if err != nil || f.names == nil {
+		return s
+	}
+	return strings.ToUpper(f.names[v][:1]) + f.names[v][1:]
+}
+
+// cronValue returns the numeric value of a single value in a field.
+func cronValue(f cronField, s string) int {
+	v, _ := f.value(s)
+	return v
+}
+
+// isCronValue reports whether a field is a single number.
+func isCronValue(s string) bool {
+	_, err := strconv.Atoi(s)
+	return err == nil
+}
+
+func allCronValues(atoms []string) bool {
+	for _, a := range atoms {
+		if !isCronValue(a) {
+			return false
+		}
+	}
+	return true
+}
+
+// joinList joins items as "a, b and c".
+func joinList(items []string) string {
+	if len(items) < 2 {
+		return strings.Join(items, "")
+	}
+	return strings.Join(items[:len(items)-1], ", ") + " and " + items[len(items)-1]
 }
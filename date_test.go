@@ -3,6 +3,8 @@ package sprig
 import (
 	"testing"
 	"time"
+
+	"github.com/stretchr/testify/assert"
 )
 
 func TestHtmlDate(t *testing.T) {
@@ -120,6 +122,29 @@ func TestDurationRound(t *testing.T) {
 	if err := runtv(tpl, "3mo", map[string]interface{}{"Time": "2400h5s"}); err != nil {
 		t.Error(err)
 	}
+
+	// Strings that are not durations are parsed as dates.
+	now := time.Date(2024, 3, 11, 12, 0, 0, 0, time.UTC)
+	c := clock(func() time.Time { return now })
+	for input, want := range map[interface{}]string{
+		"2024-03-09T12:00:00Z":   "2d",
+		"2024-03-11T09:30:00Z":   "2h",
+		90 * time.Minute:         "1h",
+		now.Add(-time.Hour * 50): "2d",
+	} {
+		got, err := c.durationRound(input)
+		assert.NoError(t, err, input)
+		assert.Equal(t, want, got, input)
+	}
+
+	_, err := c.durationRound("not a date")
+	var perr *ParseError
+	if assert.ErrorAs(t, err, &perr) {
+		assert.Equal(t, "durationRound", perr.Func)
+	}
+	_, err = c.durationRound([]int{1})
+	var terr *TypeError
+	assert.ErrorAs(t, err, &terr)
 }
 
 func TestDateModify(t *testing.T) {
@@ -145,3 +170,316 @@ func TestDateMustModifyReturnsErr(t *testing.T) {
 		t.Error("expected err, got nil")
 	}
 }
+
+func TestParseDate(t *testing.T) {
+	want := time.Date(2019, 6, 13, 20, 39, 39, 0, time.UTC)
+	for _, date := range []interface{}{
+		want,
+		&want,
+		1560458379,
+		int64(1560458379),
+		uint32(1560458379),
+		1560458379.0,
+		"1560458379",
+		"1560458379000",
+		"1560458379000000",
+		"1560458379000000000",
+		"2019-06-13T20:39:39Z",
+		"2019-06-13T22:39:39+02:00",
+		"2019-06-13T20:39:39.000Z",
+		"2019-06-13 20:39:39 +0000",
+		"2019-06-13 20:39:39 +0000 UTC",
+		"Thu, 13 Jun 2019 20:39:39 +0000",
+		"Thu, 13 Jun 2019 20:39:39 GMT",
+		"13/Jun/2019:20:39:39 +0000",
+		"20190613T203939Z",
+		" 2019-06-13T20:39:39Z\n",
+	} {
+		got, err := mustParseDate(date)
+		if assert.NoError(t, err, "%#v", date) {
+			assert.True(t, want.Equal(got), "%#v: got %s", date, got)
+		}
+	}
+
+	got, err := mustParseDate("1560458379.5")
+	assert.NoError(t, err)
+	assert.Equal(t, want.Add(500*time.Millisecond).UnixNano(), got.UnixNano())
+
+	got, err = mustParseDate("2019-06-13")
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2019, 6, 13, 0, 0, 0, 0, time.Local), got)
+
+	// Eight digits are an ISO 8601 basic date, not seconds since the epoch.
+	got, err = mustParseDate("20240107")
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 1, 7, 0, 0, 0, 0, time.Local), got)
+	got, err = mustParseDate("20241307")
+	assert.NoError(t, err)
+	assert.Equal(t, time.Unix(20241307, 0), got)
+
+	// Numbers are always seconds; only strings have their unit guessed.
+	got, err = mustParseDate(int64(1560458379000))
+	assert.NoError(t, err)
+	assert.Equal(t, time.Unix(1560458379000, 0), got)
+	assert.NoError(t, runtv(`{{ unixEpoch .Time }}`, "100000000000", map[string]interface{}{"Time": int64(1e11)}))
+
+	for _, date := range []interface{}{"", "yesterday", "2019-13-01", nil, []int{1}} {
+		_, err := mustParseDate(date)
+		assert.Error(t, err, "%#v", date)
+		assert.True(t, parseDate(date).IsZero())
+	}
+
+	_, err = mustParseDate("13.06.2019")
+	var perr *ParseError
+	if assert.ErrorAs(t, err, &perr) {
+		assert.Equal(t, "parseDate", perr.Func)
+	}
+	_, err = mustParseDate(true)
+	var terr *TypeError
+	if assert.ErrorAs(t, err, &terr) {
+		assert.Equal(t, "bool", terr.Got)
+	}
+
+	assert.NoError(t, runt(`{{ dateInZone "02/01/2006 15:04" (parseDate "2017-12-31T10:00:00Z") "UTC" }}`, "31/12/2017 10:00"))
+}
+
+func TestDateStrings(t *testing.T) {
+	vars := map[string]interface{}{"Time": "2019-06-13T20:39:39Z"}
+	assert.NoError(t, runtv(`{{ dateInZone "02 Jan 06 15:04" .Time "UTC" }}`, "13 Jun 19 20:39", vars))
+	assert.NoError(t, runtv(`{{ htmlDateInZone .Time "UTC" }}`, "2019-06-13", vars))
+	assert.NoError(t, runtv(`{{ unixEpoch .Time }}`, "1560458379", vars))
+	assert.NoError(t, runtv(`{{ dateModify "1h" .Time | unixEpoch }}`, "1560461979", vars))
+	assert.NoError(t, runtv(`{{ mustDateInZone "15:04" "1560458379000" "UTC" }}`, "20:39", vars))
+
+	_, err := runRaw(`{{ mustDate "2006" "tomorrow" }}`, nil)
+	assert.ErrorContains(t, err, "dateInZone: unrecognized date format")
+	_, err = runRaw(`{{ mustAgo .Time }}`, map[string]interface{}{"Time": nil})
+	assert.Error(t, err)
+	_, err = runRaw(`{{ unixEpoch "tomorrow" }}`, nil)
+	assert.Error(t, err)
+	_, err = runRaw(`{{ mustDateModify "1h" "tomorrow" }}`, nil)
+	assert.Error(t, err)
+}
+
+func TestDateAdd(t *testing.T) {
+	base := time.Date(2020, 1, 31, 10, 0, 0, 0, time.UTC)
+	tests := map[string]time.Time{
+		"+1 month":            time.Date(2020, 2, 29, 10, 0, 0, 0, time.UTC),
+		"1mo":                 time.Date(2020, 2, 29, 10, 0, 0, 0, time.UTC),
+		"2 months":            time.Date(2020, 3, 31, 10, 0, 0, 0, time.UTC),
+		"-2 months 1 day":     time.Date(2019, 11, 29, 10, 0, 0, 0, time.UTC),
+		"1 year, -1 week +1d": time.Date(2021, 1, 25, 10, 0, 0, 0, time.UTC),
+		"1y1mo":               time.Date(2021, 2, 28, 10, 0, 0, 0, time.UTC),
+		"3 days 2 hours 30m":  time.Date(2020, 2, 3, 12, 30, 0, 0, time.UTC),
+		"next monday":         time.Date(2020, 2, 3, 10, 0, 0, 0, time.UTC),
+		"Next Friday":         time.Date(2020, 2, 7, 10, 0, 0, 0, time.UTC),
+		"last fri":            time.Date(2020, 1, 24, 10, 0, 0, 0, time.UTC),
+		"last Thursday":       time.Date(2020, 1, 30, 10, 0, 0, 0, time.UTC),
+	}
+	for mod, want := range tests {
+		got, err := mustDateAdd(mod, base)
+		if assert.NoError(t, err, mod) {
+			assert.Equal(t, want, got, mod)
+		}
+	}
+
+	leap := time.Date(2020, 2, 29, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, time.Date(2021, 2, 28, 0, 0, 0, 0, time.UTC), dateAdd("1 year", leap))
+	assert.Equal(t, time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC), dateAdd("4 years", leap))
+
+	for _, mod := range []string{"", "1 fortnight", "next someday", "soon", "1 day and"} {
+		_, err := mustDateAdd(mod, base)
+		var perr *ParseError
+		assert.ErrorAs(t, err, &perr, mod)
+		assert.Equal(t, base, dateAdd(mod, base), mod)
+	}
+
+	assert.NoError(t, runt(`{{ "2020-01-31" | dateAdd "+1 month" | strftime "%F" }}`, "2020-02-29"))
+}
+
+func TestStartEndOf(t *testing.T) {
+	// A Wednesday.
+	tm := time.Date(2020, 5, 13, 15, 4, 5, 6, time.UTC)
+	tests := []struct {
+		unit       string
+		start, end string
+	}{
+		{"minute", "2020-05-13T15:04:00Z", "2020-05-13T15:04:59.999999999Z"},
+		{"hour", "2020-05-13T15:00:00Z", "2020-05-13T15:59:59.999999999Z"},
+		{"day", "2020-05-13T00:00:00Z", "2020-05-13T23:59:59.999999999Z"},
+		{"week", "2020-05-11T00:00:00Z", "2020-05-17T23:59:59.999999999Z"},
+		{"Month", "2020-05-01T00:00:00Z", "2020-05-31T23:59:59.999999999Z"},
+		{"quarter", "2020-04-01T00:00:00Z", "2020-06-30T23:59:59.999999999Z"},
+		{"year", "2020-01-01T00:00:00Z", "2020-12-31T23:59:59.999999999Z"},
+	}
+	for _, tt := range tests {
+		start, err := startOf(tt.unit, tm)
+		if assert.NoError(t, err, tt.unit) {
+			assert.Equal(t, tt.start, start.Format(time.RFC3339Nano), tt.unit)
+		}
+		end, err := endOf(tt.unit, tm)
+		if assert.NoError(t, err, tt.unit) {
+			assert.Equal(t, tt.end, end.Format(time.RFC3339Nano), tt.unit)
+		}
+	}
+
+	// 23:30 UTC is already the next day in Tokyo.
+	late := time.Date(2020, 5, 31, 23, 30, 0, 0, time.UTC)
+	start, err := startOf("month", late, "Asia/Tokyo")
+	assert.NoError(t, err)
+	assert.Equal(t, "2020-06-01T00:00:00+09:00", start.Format(time.RFC3339))
+
+	_, err = startOf("decade", tm)
+	assert.ErrorContains(t, err, `startOf: unknown period "decade"`)
+	_, err = endOf("day", tm, "Nowhere/Special")
+	assert.Error(t, err)
+	_, err = endOf("day", tm, "UTC", "UTC")
+	assert.Error(t, err)
+
+	assert.NoError(t, runt(`{{ startOf "week" "2020-05-13T15:04:05Z" | strftime "%F %T" }}`, "2020-05-11 00:00:00"))
+	assert.NoError(t, runt(`{{ endOf "quarter" "2020-05-13T15:04:05+02:00" "UTC" | strftime "%F %T %Z" }}`, "2020-06-30 23:59:59 UTC"))
+}
+
+func TestCalendarParts(t *testing.T) {
+	tm, err := dateTruncate("15m", "2020-05-13T15:44:05Z")
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2020, 5, 13, 15, 30, 0, 0, time.UTC), tm)
+	_, err = dateTruncate("quarter", "2020-05-13T15:44:05Z")
+	assert.Error(t, err)
+
+	assert.NoError(t, runt(`{{ weekday "2020-05-13" }}`, "Wednesday"))
+	assert.NoError(t, runt(`{{ isoWeek "2021-01-03" }} {{ isoWeek "2021-01-04" }}`, "53 1"))
+	assert.NoError(t, runt(`{{ dayOfYear "2020-12-31" }}`, "366"))
+	assert.NoError(t, runt(`{{ daysBetween "2020-02-01" "2020-03-01" }}`, "29"))
+	assert.NoError(t, runt(`{{ daysBetween "2020-03-01T23:00:00Z" "2020-03-02T01:00:00Z" }}`, "1"))
+	assert.NoError(t, runt(`{{ daysBetween "2020-03-02" "2020-03-01" }}`, "-1"))
+
+	_, err = weekday("someday")
+	assert.Error(t, err)
+	_, err = daysBetween("2020-03-02", "never")
+	assert.Error(t, err)
+}
+
+func TestCronNext(t *testing.T) {
+	from := time.Date(2020, 1, 31, 10, 15, 30, 0, time.UTC)
+	tests := []struct {
+		expr string
+		want []string
+	}{
+		{"*/20 * * * *", []string{"2020-01-31T10:20:00Z", "2020-01-31T10:40:00Z", "2020-01-31T11:00:00Z"}},
+		{"0 3 * * *", []string{"2020-02-01T03:00:00Z", "2020-02-02T03:00:00Z", "2020-02-03T03:00:00Z"}},
+		{"30 9 * * mon-fri", []string{"2020-02-03T09:30:00Z", "2020-02-04T09:30:00Z", "2020-02-05T09:30:00Z"}},
+		{"0 0 29 2 *", []string{"2020-02-29T00:00:00Z", "2024-02-29T00:00:00Z", "2028-02-29T00:00:00Z"}},
+		{"0 0 31 * *", []string{"2020-03-31T00:00:00Z", "2020-05-31T00:00:00Z", "2020-07-31T00:00:00Z"}},
+		{"0 0 1 * 0", []string{"2020-02-01T00:00:00Z", "2020-02-02T00:00:00Z", "2020-02-09T00:00:00Z"}},
+		// A day field starting with * only narrows the other one.
+		{"0 0 */10 * 7", []string{"2020-03-01T00:00:00Z", "2020-05-31T00:00:00Z", "2020-06-21T00:00:00Z"}},
+		{"45 */5 10 * * *", []string{"2020-01-31T10:15:45Z", "2020-01-31T10:20:45Z", "2020-01-31T10:25:45Z"}},
+		{"@monthly", []string{"2020-02-01T00:00:00Z", "2020-03-01T00:00:00Z", "2020-04-01T00:00:00Z"}},
+		{"0 12 * JAN,jul ?", []string{"2020-01-31T12:00:00Z", "2020-07-01T12:00:00Z", "2020-07-02T12:00:00Z"}},
+		{"CRON_TZ=Asia/Tokyo 0 9 * * *", []string{"2020-02-01T09:00:00+09:00", "2020-02-02T09:00:00+09:00", "2020-02-03T09:00:00+09:00"}},
+	}
+	for _, tt := range tests {
+		times, err := systemClock.cronNext(tt.expr, 3, from)
+		if assert.NoError(t, err, tt.expr) {
+			got := make([]string, len(times))
+			for i, tm := range times {
+				got[i] = tm.Format(time.RFC3339)
+			}
+			assert.Equal(t, tt.want, got, tt.expr)
+		}
+	}
+
+	// 02:30 does not exist in New York on 2020-03-08.
+	ny, err := time.LoadLocation("America/New_York")
+	if assert.NoError(t, err) {
+		times, err := systemClock.cronNext("30 2 * * *", 2, time.Date(2020, 3, 7, 12, 0, 0, 0, ny))
+		assert.NoError(t, err)
+		assert.Equal(t, []time.Time{time.Date(2020, 3, 9, 2, 30, 0, 0, ny), time.Date(2020, 3, 10, 2, 30, 0, 0, ny)}, times)
+	}
+
+	prev, err := systemClock.cronPrev("0 0 1 * *", 2, from)
+	assert.NoError(t, err)
+	assert.Equal(t, []time.Time{time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2019, 12, 1, 0, 0, 0, 0, time.UTC)}, prev)
+	prev, err = systemClock.cronPrev("*/20 * * * *", 1, from)
+	assert.NoError(t, err)
+	assert.Equal(t, []time.Time{time.Date(2020, 1, 31, 10, 0, 0, 0, time.UTC)}, prev)
+
+	exact, err := systemClock.cronNext("0 3 * * *", 1, "2020-02-01T03:00:00Z")
+	assert.NoError(t, err)
+	assert.Equal(t, "2020-02-02T03:00:00Z", exact[0].Format(time.RFC3339))
+
+	empty, err := systemClock.cronNext("0 3 * * *", 0, from)
+	assert.NoError(t, err)
+	assert.Empty(t, empty)
+
+	_, err = systemClock.cronNext("0 0 30 2 *", 1, from)
+	assert.ErrorContains(t, err, "does not fire")
+	_, err = systemClock.cronNext("0 3 * * *", -1, from)
+	assert.Error(t, err)
+	most, err := systemClock.cronNext("* * * * *", 1000, from)
+	assert.NoError(t, err)
+	assert.Len(t, most, 1000)
+	_, err = systemClock.cronPrev("* * * * *", 1000000000, from)
+	assert.EqualError(t, err, "cronPrev: count must be at most 1000, got 1000000000")
+	_, err = systemClock.cronNext("0 3 * * *", 1, "never")
+	assert.Error(t, err)
+
+	fixed := time.Date(2020, 2, 29, 12, 0, 0, 0, time.UTC)
+	fm := New(WithClock(func() time.Time { return fixed })).TxtFuncMap()
+	assert.NoError(t, runtFuncs(fm, `{{ range cronNext "0 0 * * *" 2 }}{{ . | strftime "%F " }}{{ end }}`, "2020-03-01 2020-03-02 "))
+	assert.NoError(t, runtFuncs(fm, `{{ index (cronPrev "@hourly" 1) 0 | strftime "%T" }}`, "11:00:00"))
+}
+
+func TestCronValid(t *testing.T) {
+	for _, expr := range []string{
+		"* * * * *", "0 0 * * *", "*/5 1-5,10 * * 1-5", "0 0 1 jan-mar *", "0 0 * * sun,7",
+		"0 0 0 * * ?", "@yearly", "@Daily", "TZ=UTC 0 0 * * *", "5/15 * * * *",
+	} {
+		assert.True(t, cronValid(expr), expr)
+	}
+	for _, expr := range []string{
+		"", "* * * *", "* * * * * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "* * * 13 *",
+		"* * * * 8", "5-1 * * * *", "*/0 * * * *", "? * * * *", "@every 5m", "TZ=Nowhere 0 0 * * *",
+		"* * L * *",
+	} {
+		assert.False(t, cronValid(expr), expr)
+	}
+	_, err := cronDescribe("* * * *")
+	var perr *ParseError
+	if assert.ErrorAs(t, err, &perr) {
+		assert.Equal(t, "cronDescribe", perr.Func)
+	}
+}
+
+func TestCronDescribe(t *testing.T) {
+	tests := map[string]string{
+		"* * * * *":                    "Every minute",
+		"*/5 * * * *":                  "Every 5 minutes",
+		"0 3 * * *":                    "At 03:00",
+		"30 9,17 * * *":                "At 09:30 and 17:30",
+		"15 30 10 * * *":               "At 10:30:15",
+		"0 * * * *":                    "At minute 0 of every hour",
+		"0,30 * * * *":                 "At minutes 0 and 30 of every hour",
+		"*/15 9 * * *":                 "Every 15 minutes, during hour 9",
+		"0 9-17 * * 1-5":               "At minute 0, during hours 9 through 17, on Monday through Friday",
+		"0 */2 * * *":                  "At minute 0, every 2 hours",
+		"0 8-18/2 * * *":               "At minute 0, every 2 hours from 8 through 18",
+		"0 0 1 * *":                    "At 00:00, on day 1 of the month",
+		"0 0 1,15 * *":                 "At 00:00, on days 1 and 15 of the month",
+		"0 0 */2 * *":                  "At 00:00, every 2 days",
+		"0 0 1 * MON":                  "At 00:00, on day 1 of the month, or on Monday",
+		"0 0 * * sat,sun":              "At 00:00, on Saturday and Sunday",
+		"0 0 1 */3 *":                  "At 00:00, on day 1 of the month, every 3 months",
+		"0 0 1 jan,jul *":              "At 00:00, on day 1 of the month, in January and July",
+		"30 * * * * *":                 "At second 30, every minute",
+		"@weekly":                      "At 00:00, on Sunday",
+		"CRON_TZ=Europe/Berlin @daily": "At 00:00, Europe/Berlin time",
+	}
+	for expr, want := range tests {
+		got, err := cronDescribe(expr)
+		if assert.NoError(t, err, expr) {
+			assert.Equal(t, want, got, expr)
+		}
+	}
+}
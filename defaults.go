@@ -3,10 +3,14 @@ package sprig
 import (
 	"bytes"
 	"encoding/json"
+	"io"
 	"math/rand"
 	"reflect"
 	"strings"
 	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
 )
 
 func init() {
@@ -100,8 +104,10 @@ func fromJson(v string) interface{} {
 // mustFromJson decodes JSON into a structured value, returning errors.
 func mustFromJson(v string) (interface{}, error) {
 	var output interface{}
-	err := json.Unmarshal([]byte(v), &output)
-	return output, err
+	if err := json.Unmarshal([]byte(v), &output); err != nil {
+		return nil, &ParseError{Func: "fromJson", Input: v, Err: err}
+	}
+	return output, nil
 }
 
 // toJson encodes an item into a JSON string
@@ -113,7 +119,7 @@ func toJson(v interface{}) string {
 func mustToJson(v interface{}) (string, error) {
 	output, err := json.Marshal(v)
 	if err != nil {
-		return "", err
+		return "", &FuncError{Func: "toJson", Err: err}
 	}
 	return string(output), nil
 }
@@ -127,7 +133,7 @@ func toPrettyJson(v interface{}) string {
 func mustToPrettyJson(v interface{}) (string, error) {
 	output, err := json.MarshalIndent(v, "", "  ")
 	if err != nil {
-		return "", err
+		return "", &FuncError{Func: "toPrettyJson", Err: err}
 	}
 	return string(output), nil
 }
@@ -148,11 +154,215 @@ func mustToRawJson(v interface{}) (string, error) {
 	enc.SetEscapeHTML(false)
 	err := enc.Encode(&v)
 	if err != nil {
-		return "", err
+		return "", &FuncError{Func: "toRawJson", Err: err}
 	}
 	return strings.TrimSuffix(buf.String(), "\n"), nil
 }
 
+// fromYaml decodes a YAML document into a structured value, ignoring errors.
+func fromYaml(v string) interface{} {
+	output, _ := mustFromYaml(v)
+	return output
+}
+
+// mustFromYaml decodes a YAML document into a structured value, returning errors.
+//
+// Mappings are decoded as map[string]interface{} so that the result works with
+// the dict functions.
+func mustFromYaml(v string) (interface{}, error) {
+	var output interface{}
+	if err := unmarshalYaml(v, &output); err != nil {
+		return nil, &ParseError{Func: "fromYaml", Input: v, Err: err}
+	}
+	return normalizeYaml(output), nil
+}
+
+// fromYamlArray decodes a YAML sequence into a list, ignoring errors.
+func fromYamlArray(v string) []interface{} {
+	output, _ := mustFromYamlArray(v)
+	return output
+}
+
+// mustFromYamlArray decodes a YAML sequence into a list, returning errors.
+func mustFromYamlArray(v string) ([]interface{}, error) {
+	var output []interface{}
+	if err := unmarshalYaml(v, &output); err != nil {
+		return nil, &ParseError{Func: "fromYamlArray", Input: v, Err: err}
+	}
+	for i, item := range output {
+		output[i] = normalizeYaml(item)
+	}
+	return output, nil
+}
+
+// fromYamlAll decodes a multi-document YAML stream into a list with one item
+// per document, ignoring errors.
+func fromYamlAll(v string) []interface{} {
+	output, _ := mustFromYamlAll(v)
+	return output
+}
+
+// mustFromYamlAll decodes a multi-document YAML stream into a list with one
+// item per document, returning errors.
+func mustFromYamlAll(v string) ([]interface{}, error) {
+	output := []interface{}{}
+	dec := yaml.NewDecoder(strings.NewReader(v))
+	for {
+		var node yaml.Node
+		err := dec.Decode(&node)
+		if err == io.EOF {
+			return output, nil
+		}
+		var doc interface{}
+		if err == nil {
+			err = decodeYamlNode(&node, &doc)
+		}
+		if err != nil {
+			return nil, &ParseError{Func: "fromYamlAll", Input: v, Err: err}
+		}
+		output = append(output, normalizeYaml(doc))
+	}
+}
+
+// unmarshalYaml decodes a YAML document into out like yaml.Unmarshal, but
+// keeps timestamps as strings; see decodeYamlNode.
+func unmarshalYaml(v string, out interface{}) error {
+	var node yaml.Node
+	if err := yaml.Unmarshal([]byte(v), &node); err != nil {
+		return err
+	}
+	return decodeYamlNode(&node, out)
+}
+
+// decodeYamlNode decodes node into out. Unquoted scalars that look like
+// timestamps, such as 2001-12-14, are decoded as their original text rather
+// than as a time.Time, so that they survive a round trip through toYaml or
+// toJson unchanged.
+func decodeYamlNode(node *yaml.Node, out interface{}) error {
+	if node.Kind == 0 {
+		return nil
+	}
+	keepYamlTimestamps(node)
+	return node.Decode(out)
+}
+
+// keepYamlTimestamps retags the timestamp scalars under node as strings.
+func keepYamlTimestamps(node *yaml.Node) {
+	if node.Kind == yaml.ScalarNode && node.ShortTag() == "!!timestamp" {
+		node.Tag = "!!str"
+	}
+	for _, child := range node.Content {
+		keepYamlTimestamps(child)
+	}
+}
+
+// toYaml encodes an item into a YAML string
+func toYaml(v interface{}) string {
+	output, _ := mustToYaml(v)
+	return output
+}
+
+// mustToYaml encodes an item into a YAML string, returning errors.
+func mustToYaml(v interface{}) (string, error) {
+	buf := new(bytes.Buffer)
+	enc := yaml.NewEncoder(buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(v); err != nil {
+		return "", &FuncError{Func: "toYaml", Err: err}
+	}
+	if err := enc.Close(); err != nil {
+		return "", &FuncError{Func: "toYaml", Err: err}
+	}
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
+
+// normalizeYaml converts the map[interface{}]interface{} values produced for
+// mappings with non-string keys into map[string]interface{}.
+func normalizeYaml(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, val := range v {
+			m[strval(k)] = normalizeYaml(val)
+		}
+		return m
+	case map[string]interface{}:
+		for k, val := range v {
+			v[k] = normalizeYaml(val)
+		}
+		return v
+	case []interface{}:
+		for i, val := range v {
+			v[i] = normalizeYaml(val)
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+// fromToml decodes a TOML document into a dict, ignoring errors.
+func fromToml(v string) map[string]interface{} {
+	output, _ := mustFromToml(v)
+	return output
+}
+
+// mustFromToml decodes a TOML document into a dict, returning errors.
+func mustFromToml(v string) (map[string]interface{}, error) {
+	output := map[string]interface{}{}
+	if _, err := toml.Decode(v, &output); err != nil {
+		return nil, &ParseError{Func: "fromToml", Input: v, Err: err}
+	}
+	return normalizeToml(output).(map[string]interface{}), nil
+}
+
+// toToml encodes a dict or struct into a TOML string
+func toToml(v interface{}) string {
+	output, _ := mustToToml(v)
+	return output
+}
+
+// mustToToml encodes a dict or struct into a TOML string, returning errors.
+//
+// Keys are written in sorted order, so the output is deterministic. A TOML
+// document is a table, so anything other than a map or struct is an error.
+func mustToToml(v interface{}) (string, error) {
+	if k := reflect.Indirect(reflect.ValueOf(v)).Kind(); k != reflect.Map && k != reflect.Struct {
+		return "", &TypeError{Func: "toToml", Arg: "value", Got: typeName(v), Want: "a dict or struct"}
+	}
+	buf := new(bytes.Buffer)
+	if err := toml.NewEncoder(buf).Encode(v); err != nil {
+		return "", &FuncError{Func: "toToml", Err: err}
+	}
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
+
+// normalizeToml converts the []map[string]interface{} values produced for
+// arrays of tables into []interface{} so that they work with the list
+// functions.
+func normalizeToml(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, val := range v {
+			v[k] = normalizeToml(val)
+		}
+		return v
+	case []map[string]interface{}:
+		l := make([]interface{}, len(v))
+		for i, val := range v {
+			l[i] = normalizeToml(val)
+		}
+		return l
+	case []interface{}:
+		for i, val := range v {
+			v[i] = normalizeToml(val)
+		}
+		return v
+	default:
+		return v
+	}
+}
+
 // ternary returns the first value if the last value is true, otherwise returns the second value.
 func ternary(vt interface{}, vf interface{}, v bool) interface{} {
 	if v {
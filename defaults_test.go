@@ -173,6 +173,161 @@ func TestToRawJson(t *testing.T) {
 	}
 }
 
+func TestFromYaml(t *testing.T) {
+	dict := map[string]interface{}{"Input": "foo: 55\nbar:\n  baz: [1, 2]\n  1: one\n"}
+
+	tpl := `{{(.Input | fromYaml).foo}}`
+	if err := runtv(tpl, "55", dict); err != nil {
+		t.Error(err)
+	}
+
+	// Mappings with non-string keys are still usable with the dict functions.
+	tpl = `{{ dig "bar" "1" "" (.Input | fromYaml) }} {{ dig "bar" "baz" "" (.Input | fromYaml) }}`
+	if err := runtv(tpl, "one [1 2]", dict); err != nil {
+		t.Error(err)
+	}
+
+	// Timestamps are kept as the text they were written as.
+	dates := map[string]interface{}{"Input": "a: 2001-12-14\nb: 2001-12-14t21:59:43.10-05:00\nc: [2024-01-07]\n"}
+	tpl = `{{ (.Input | fromYaml).a }} {{ (.Input | fromYaml).b }}`
+	if err := runtv(tpl, "2001-12-14 2001-12-14t21:59:43.10-05:00", dates); err != nil {
+		t.Error(err)
+	}
+	tpl = `{{ .Input | fromYaml | toJson }}`
+	if err := runtv(tpl, `{"a":"2001-12-14","b":"2001-12-14t21:59:43.10-05:00","c":["2024-01-07"]}`, dates); err != nil {
+		t.Error(err)
+	}
+	tpl = `{{ "- 2001-12-14" | fromYamlArray | first }} {{ "a: 2001-12-14\n---\n2024-01-07" | fromYamlAll | toJson }}`
+	if err := runt(tpl, `2001-12-14 [{"a":"2001-12-14"},"2024-01-07"]`); err != nil {
+		t.Error(err)
+	}
+
+	tpl = `{{ "foo: [" | fromYaml }}`
+	if err := runt(tpl, "<no value>"); err != nil {
+		t.Error(err)
+	}
+	tpl = `{{ "foo: [" | mustFromYaml }}`
+	if err := runt(tpl, ""); err == nil {
+		t.Error("expected error for invalid YAML")
+	}
+}
+
+func TestFromYamlArray(t *testing.T) {
+	tpl := `{{ "- a\n- b: 1\n" | fromYamlArray | len }}`
+	if err := runt(tpl, "2"); err != nil {
+		t.Error(err)
+	}
+	tpl = `{{ index ("- a\n- b: 1\n" | fromYamlArray) 1 | keys }}`
+	if err := runt(tpl, "[b]"); err != nil {
+		t.Error(err)
+	}
+	tpl = `{{ "foo: bar" | mustFromYamlArray }}`
+	if err := runt(tpl, ""); err == nil {
+		t.Error("expected error for mapping")
+	}
+}
+
+func TestFromYamlAll(t *testing.T) {
+	dict := map[string]interface{}{"Input": "a: 1\n---\nb: 2\n---\n- c\n"}
+	tpl := `{{ range .Input | fromYamlAll }}{{ . }};{{ end }}`
+	if err := runtv(tpl, "map[a:1];map[b:2];[c];", dict); err != nil {
+		t.Error(err)
+	}
+	tpl = `{{ "a: 1\n---\nb: [" | mustFromYamlAll }}`
+	if err := runt(tpl, ""); err == nil {
+		t.Error("expected error for invalid YAML")
+	}
+}
+
+func TestToYaml(t *testing.T) {
+	dict := map[string]interface{}{"Top": map[string]interface{}{"bool": true, "string": "test", "list": []interface{}{1, "two"}}}
+
+	tpl := `{{.Top | toYaml}}`
+	expected := `bool: true
+list:
+  - 1
+  - two
+string: test`
+	if err := runtv(tpl, expected, dict); err != nil {
+		t.Error(err)
+	}
+
+	tpl = `{{ .Top | toYaml | fromYaml | toJson }}`
+	expected = `{"bool":true,"list":[1,"two"],"string":"test"}`
+	if err := runtv(tpl, expected, dict); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestFromToml(t *testing.T) {
+	dict := map[string]interface{}{"Input": `title = "demo"
+
+[owner]
+name = "tom"
+
+[[servers]]
+host = "a"
+
+[[servers]]
+host = "b"
+`}
+
+	tpl := `{{ (.Input | fromToml).title }} {{ dig "owner" "name" "" (.Input | fromToml) }}`
+	if err := runtv(tpl, "demo tom", dict); err != nil {
+		t.Error(err)
+	}
+
+	if err := runtv(`{{ range (.Input | fromToml).servers }}{{ .host }};{{ end }}`, "a;b;", dict); err != nil {
+		t.Error(err)
+	}
+	if err := runtv(`{{ (.Input | fromToml).servers | first | keys }}`, "[host]", dict); err != nil {
+		t.Error(err)
+	}
+
+	if err := runt(`{{ "a = " | fromToml | len }}`, "0"); err != nil {
+		t.Error(err)
+	}
+	if err := runt(`{{ "a = " | mustFromToml }}`, ""); err == nil {
+		t.Error("expected error for invalid TOML")
+	}
+}
+
+func TestToToml(t *testing.T) {
+	dict := map[string]interface{}{"Top": map[string]interface{}{
+		"name":  "test",
+		"count": 42,
+		"db":    map[string]interface{}{"port": 5432, "host": "localhost"},
+	}}
+
+	tpl := `{{ .Top | toToml }}`
+	expected := `count = 42
+name = "test"
+
+[db]
+  host = "localhost"
+  port = 5432`
+	if err := runtv(tpl, expected, dict); err != nil {
+		t.Error(err)
+	}
+
+	tpl = `{{ .Top | toToml | fromToml | toJson }}`
+	expected = `{"count":42,"db":{"host":"localhost","port":5432},"name":"test"}`
+	if err := runtv(tpl, expected, dict); err != nil {
+		t.Error(err)
+	}
+
+	for _, tpl := range []string{`{{ list 1 2 | mustToToml }}`, `{{ "text" | mustToToml }}`, `{{ mustToToml nil }}`} {
+		_, err := runRaw(tpl, nil)
+		var terr *TypeError
+		if assert.ErrorAs(t, err, &terr, tpl) {
+			assert.Equal(t, "toToml", terr.Func)
+		}
+	}
+	if err := runt(`{{ list 1 2 | toToml }}`, ""); err != nil {
+		t.Error(err)
+	}
+}
+
 func TestTernary(t *testing.T) {
 	tpl := `{{true | ternary "foo" "bar"}}`
 	if err := runt(tpl, "foo"); err != nil {
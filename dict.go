@@ -1,6 +1,9 @@
 package sprig
 
 import (
+	"fmt"
+	"strings"
+
 	"dario.cat/mergo"
 	"github.com/mitchellh/copystructure"
 )
@@ -100,7 +103,7 @@ func merge(dst map[string]interface{}, srcs ...map[string]interface{}) interface
 func mustMerge(dst map[string]interface{}, srcs ...map[string]interface{}) (interface{}, error) {
 	for _, src := range srcs {
 		if err := mergo.Merge(&dst, src); err != nil {
-			return nil, err
+			return nil, &FuncError{Func: "merge", Err: err}
 		}
 	}
 	return dst, nil
@@ -119,7 +122,7 @@ func mergeOverwrite(dst map[string]interface{}, srcs ...map[string]interface{})
 func mustMergeOverwrite(dst map[string]interface{}, srcs ...map[string]interface{}) (interface{}, error) {
 	for _, src := range srcs {
 		if err := mergo.MergeWithOverwrite(&dst, src); err != nil {
-			return nil, err
+			return nil, &FuncError{Func: "mergeOverwrite", Err: err}
 		}
 	}
 	return dst, nil
@@ -137,38 +140,63 @@ func values(dict map[string]interface{}) []interface{} {
 func deepCopy(i interface{}) interface{} {
 	c, err := mustDeepCopy(i)
 	if err != nil {
-		panic("deepCopy error: " + err.Error())
+		panic(err)
 	}
 
 	return c
 }
 
 func mustDeepCopy(i interface{}) (interface{}, error) {
-	return copystructure.Copy(i)
+	c, err := copystructure.Copy(i)
+	if err != nil {
+		return nil, &FuncError{Func: "deepCopy", Err: err}
+	}
+	return c, nil
 }
 
 func dig(ps ...interface{}) (interface{}, error) {
 	if len(ps) < 3 {
-		panic("dig needs at least three arguments")
+		return nil, &FuncError{Func: "dig", Err: fmt.Errorf("needs at least three arguments, got %d", len(ps))}
+	}
+	dict, ok := ps[len(ps)-1].(map[string]interface{})
+	if !ok {
+		return nil, &TypeError{Func: "dig", Arg: "last argument", Got: typeName(ps[len(ps)-1]), Want: "map[string]interface {}"}
 	}
-	dict := ps[len(ps)-1].(map[string]interface{})
 	def := ps[len(ps)-2]
 	ks := make([]string, len(ps)-2)
 	for i := 0; i < len(ks); i++ {
-		ks[i] = ps[i].(string)
+		k, ok := ps[i].(string)
+		if !ok {
+			return nil, &TypeError{Func: "dig", Arg: fmt.Sprintf("key %d", i+1), Got: typeName(ps[i]), Want: "string"}
+		}
+		ks[i] = k
 	}
 
-	return digFromDict(dict, def, ks)
+	return digFromDict(dict, def, ks, 0)
 }
 
-func digFromDict(dict map[string]interface{}, d interface{}, ks []string) (interface{}, error) {
-	k, ns := ks[0], ks[1:len(ks)]
-	step, has := dict[k]
+// digFromDict looks up ks[i:] in dict. A missing key, or a nil value before
+// the last key, yields the default d; a nil value at the last key is returned
+// as it is.
+func digFromDict(dict map[string]interface{}, d interface{}, ks []string, i int) (interface{}, error) {
+	step, has := dict[ks[i]]
 	if !has {
 		return d, nil
 	}
-	if len(ns) == 0 {
+	if i == len(ks)-1 {
 		return step, nil
 	}
-	return digFromDict(step.(map[string]interface{}), d, ns)
+	if step == nil {
+		return d, nil
+	}
+	next, ok := step.(map[string]interface{})
+	if !ok {
+		return nil, &TypeError{
+			Func: "dig",
+			Arg:  fmt.Sprintf("value at %q", strings.Join(ks[:i+1], ".")),
+			Got:  typeName(step),
+			Want: "map[string]interface {}",
+		}
+	}
+	return digFromDict(next, d, ks, i+1)
 }
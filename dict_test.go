@@ -300,6 +300,8 @@ func TestDig(t *testing.T) {
 		`{{- $d := dict "a" (dict "b" (dict "c" 1)) }}{{ dig "a" "b" "z" "2" $d }}`: "2",
 		`{{ dict "a" 1 | dig "a" "" }}`:                                             "1",
 		`{{ dict "a" 1 | dig "z" "2" }}`:                                            "2",
+		`{{ dict "a" nil | dig "a" "b" "2" }}`:                                      "2",
+		`{{- $d := dict "a" (dict "b" nil) }}{{ dig "a" "b" "c" "2" $d }}`:          "2",
 	}
 
 	for tpl, expect := range tests {
@@ -307,4 +309,34 @@ func TestDig(t *testing.T) {
 			t.Error(err)
 		}
 	}
+
+	// A nil value at the last key is returned rather than the default.
+	v, err := dig("a", "2", map[string]interface{}{"a": nil})
+	assert.NoError(t, err)
+	assert.Nil(t, v)
+}
+
+func TestDigErrors(t *testing.T) {
+	d := map[string]interface{}{"a": map[string]interface{}{"b": "not a dict"}}
+
+	_, err := dig("a", "b", "c", "", d)
+	var typeErr *TypeError
+	if assert.ErrorAs(t, err, &typeErr) {
+		assert.Equal(t, "dig", typeErr.Func)
+		assert.Equal(t, `value at "a.b"`, typeErr.Arg)
+		assert.Equal(t, "string", typeErr.Got)
+	}
+
+	_, err = dig("a", 1, "", d)
+	assert.ErrorAs(t, err, &typeErr)
+	assert.Equal(t, "key 2", typeErr.Arg)
+
+	_, err = dig("a", "", "not a dict")
+	assert.ErrorAs(t, err, &typeErr)
+	assert.Equal(t, "last argument", typeErr.Arg)
+
+	_, err = dig("a", d)
+	assert.EqualError(t, err, "dig: needs at least three arguments, got 2")
+
+	assert.Error(t, runtv(`{{ dig "a" "b" "c" "" . }}`, "", d))
 }
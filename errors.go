@@ -0,0 +1,63 @@
+package sprig
+
+import "fmt"
+
+// TypeError is returned when a function receives a value of the wrong type.
+type TypeError struct {
+	// Func is the name of the function that failed.
+	Func string
+	// Arg describes the argument or value that has the wrong type.
+	Arg string
+	// Got is the type that was received.
+	Got string
+	// Want is the type that was expected.
+	Want string
+}
+
+func (e *TypeError) Error() string {
+	return fmt.Sprintf("%s: %s must be %s, got %s", e.Func, e.Arg, e.Want, e.Got)
+}
+
+// typeName returns the name of the type of v for use in errors.
+func typeName(v interface{}) string {
+	if v == nil {
+		return "nil"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+// ParseError is returned when a function cannot parse its input, such as a
+// JSON document, a date or a regular expression.
+type ParseError struct {
+	// Func is the name of the function that failed.
+	Func string
+	// Input is the text that could not be parsed.
+	Input string
+	// Err is the underlying error.
+	Err error
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Func, e.Err)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// FuncError is returned when a function fails for a reason other than a type
+// or parse error, such as a value that cannot be encoded.
+type FuncError struct {
+	// Func is the name of the function that failed.
+	Func string
+	// Err is the underlying error.
+	Err error
+}
+
+func (e *FuncError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Func, e.Err)
+}
+
+func (e *FuncError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,64 @@
+package sprig
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTypeError(t *testing.T) {
+	_, err := mustChunk(2, 5)
+	var typeErr *TypeError
+	if assert.ErrorAs(t, err, &typeErr) {
+		assert.Equal(t, &TypeError{Func: "chunk", Arg: "list", Got: "int", Want: "slice or array"}, typeErr)
+	}
+	assert.EqualError(t, err, "chunk: list must be slice or array, got int")
+
+	_, err = mustFirst(nil)
+	assert.ErrorAs(t, err, &typeErr)
+	assert.Equal(t, "nil", typeErr.Got)
+
+	// The error is also returned by the template.
+	err = runt(`{{ mustLast "foo" }}`, "")
+	assert.ErrorAs(t, err, &typeErr)
+	assert.Equal(t, "last", typeErr.Func)
+	assert.Equal(t, "string", typeErr.Got)
+}
+
+func TestParseError(t *testing.T) {
+	_, err := mustRegexMatch("[", "foo")
+	var parseErr *ParseError
+	if assert.ErrorAs(t, err, &parseErr) {
+		assert.Equal(t, "regexMatch", parseErr.Func)
+		assert.Equal(t, "[", parseErr.Input)
+	}
+	assert.EqualError(t, err, "regexMatch: error parsing regexp: missing closing ]: `[`")
+
+	_, err = mustFromJson(`{"foo":`)
+	var syntaxErr *json.SyntaxError
+	assert.ErrorAs(t, err, &parseErr)
+	assert.Equal(t, "fromJson", parseErr.Func)
+	assert.ErrorAs(t, err, &syntaxErr)
+
+	err = runt(`{{ mustToDate "2006-01-02" "nope" }}`, "")
+	assert.ErrorAs(t, err, &parseErr)
+	assert.Equal(t, "toDate", parseErr.Func)
+	assert.Equal(t, "nope", parseErr.Input)
+}
+
+func TestFuncError(t *testing.T) {
+	_, err := mustToJson(func() {})
+	var funcErr *FuncError
+	if assert.ErrorAs(t, err, &funcErr) {
+		assert.Equal(t, "toJson", funcErr.Func)
+	}
+	var typeErr *json.UnsupportedTypeError
+	assert.ErrorAs(t, err, &typeErr)
+
+	_, err = mustFromDelimited("ab", "a")
+	assert.ErrorAs(t, err, &funcErr)
+	assert.Equal(t, "fromDelimited", funcErr.Func)
+	assert.False(t, errors.As(err, new(*ParseError)))
+}
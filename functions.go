@@ -68,6 +68,7 @@ func GenericFuncMap() map[string]interface{} {
 // refer to the environment or global state.
 var nonhermeticFunctions = []string{
 	// Date functions
+	"ago",
 	"date",
 	"date_in_zone",
 	"date_modify",
@@ -76,6 +77,19 @@ var nonhermeticFunctions = []string{
 	"htmlDateInZone",
 	"dateInZone",
 	"dateModify",
+	"durationRound",
+	"mustAgo",
+	"mustDate",
+	"mustDateInZone",
+	"mustHtmlDate",
+	"mustHtmlDateInZone",
+	"dateFormat",
+	"mustDateFormat",
+	"strftime",
+	"mustStrftime",
+	"cronNext",
+	"cronPrev",
+	"humanizeTime",
 
 	// Strings
 	"randAlphaNum",
@@ -84,6 +98,39 @@ var nonhermeticFunctions = []string{
 	"randNumeric",
 	"randBytes",
 	"uuidv4",
+	"shuffle",
+
+	// Math
+	"randInt",
+
+	// Crypto
+	"argon2id",
+	"bcrypt",
+	"bcryptCost",
+	"encryptAES",
+	"encryptAESGCM",
+	"encryptAESGCMWithKDF",
+	"genCA",
+	"genCAWithKey",
+	"genCAWithOptions",
+	"genCSR",
+	"genPrivateKey",
+	"genSelfSignedCert",
+	"genSelfSignedCertWithKey",
+	"genSelfSignedCertWithOptions",
+	"genSignedCert",
+	"genSignedCertWithKey",
+	"genSignedCertWithOptions",
+	"htpasswd",
+	"signData",
+	"mustSignData",
+	"jwtSign",
+	"scryptHash",
+	"signCSR",
+	"verifyCertChain",
+	"mustVerifyCertChain",
+	"jwtVerify",
+	"mustJwtVerify",
 
 	// OS
 	"env",
@@ -91,22 +138,33 @@ var nonhermeticFunctions = []string{
 
 	// Network
 	"getHostByName",
+	"getHostsByName",
+	"lookupIPv4",
+	"lookupIPv6",
+	"lookupSRV",
+	"lookupTXT",
+	"mustGetHostByName",
+	"mustGetHostsByName",
+	"mustLookupIPv4",
+	"mustLookupIPv6",
+	"mustLookupSRV",
+	"mustLookupTXT",
 }
 
 var genericMap = map[string]interface{}{
 	"hello": func() string { return "Hello!" },
 
 	// Date functions
-	"ago":              dateAgo,
-	"date":             date,
-	"date_in_zone":     dateInZone,
+	"ago":              systemClock.dateAgo,
+	"date":             systemClock.date,
+	"date_in_zone":     systemClock.dateInZone,
 	"date_modify":      dateModify,
-	"dateInZone":       dateInZone,
+	"dateInZone":       systemClock.dateInZone,
 	"dateModify":       dateModify,
 	"duration":         duration,
-	"durationRound":    durationRound,
-	"htmlDate":         htmlDate,
-	"htmlDateInZone":   htmlDateInZone,
+	"durationRound":    systemClock.durationRound,
+	"htmlDate":         systemClock.htmlDate,
+	"htmlDateInZone":   systemClock.htmlDateInZone,
 	"must_date_modify": mustDateModify,
 	"mustDateModify":   mustDateModify,
 	"mustToDate":       mustToDate,
@@ -114,6 +172,45 @@ var genericMap = map[string]interface{}{
 	"toDate":           toDate,
 	"unixEpoch":        unixEpoch,
 
+	// Date parsing:
+	"mustAgo":            systemClock.mustDateAgo,
+	"mustDate":           systemClock.mustDate,
+	"mustDateInZone":     systemClock.mustDateInZone,
+	"mustHtmlDate":       systemClock.mustHtmlDate,
+	"mustHtmlDateInZone": systemClock.mustHtmlDateInZone,
+	"mustParseDate":      mustParseDate,
+	"parseDate":          parseDate,
+
+	// Date formats:
+	"dateFormat":     systemClock.strftime,
+	"mustDateFormat": systemClock.mustStrftime,
+	"mustStrftime":   systemClock.mustStrftime,
+	"mustStrptime":   mustStrptime,
+	"strftime":       systemClock.strftime,
+	"strptime":       strptime,
+
+	// Calendar:
+	"dateAdd":      dateAdd,
+	"dateTruncate": dateTruncate,
+	"dayOfYear":    dayOfYear,
+	"daysBetween":  daysBetween,
+	"endOf":        endOf,
+	"isoWeek":      isoWeek,
+	"mustDateAdd":  mustDateAdd,
+	"startOf":      startOf,
+	"weekday":      weekday,
+
+	// Cron:
+	"cronDescribe": cronDescribe,
+	"cronNext":     systemClock.cronNext,
+	"cronPrev":     systemClock.cronPrev,
+	"cronValid":    cronValid,
+
+	// Humanize:
+	"humanizeBetween":  systemHumanizer.humanizeBetween,
+	"humanizeDuration": systemHumanizer.humanizeDuration,
+	"humanizeTime":     systemHumanizer.humanizeTime,
+
 	// Strings
 	"abbrev":     abbrev,
 	"abbrevboth": abbrevboth,
@@ -258,6 +355,22 @@ var genericMap = map[string]interface{}{
 	"deepCopy":         deepCopy,
 	"mustDeepCopy":     mustDeepCopy,
 
+	// YAML:
+	"fromYaml":          fromYaml,
+	"fromYamlArray":     fromYamlArray,
+	"fromYamlAll":       fromYamlAll,
+	"toYaml":            toYaml,
+	"mustFromYaml":      mustFromYaml,
+	"mustFromYamlArray": mustFromYamlArray,
+	"mustFromYamlAll":   mustFromYamlAll,
+	"mustToYaml":        mustToYaml,
+
+	// TOML:
+	"fromToml":     fromToml,
+	"toToml":       toToml,
+	"mustFromToml": mustFromToml,
+	"mustToToml":   mustToToml,
+
 	// Reflection
 	"typeOf":     typeOf,
 	"typeIs":     typeIs,
@@ -271,7 +384,40 @@ var genericMap = map[string]interface{}{
 	"expandenv": os.ExpandEnv,
 
 	// Network:
-	"getHostByName": getHostByName,
+	"getHostByName":      systemLookup.getHostByName,
+	"getHostsByName":     systemLookup.getHostsByName,
+	"lookupIPv4":         systemLookup.lookupIPv4,
+	"lookupIPv6":         systemLookup.lookupIPv6,
+	"lookupSRV":          systemLookup.lookupSRV,
+	"lookupTXT":          systemLookup.lookupTXT,
+	"mustGetHostByName":  systemLookup.mustGetHostByName,
+	"mustGetHostsByName": systemLookup.mustGetHostsByName,
+	"mustLookupIPv4":     systemLookup.mustLookupIPv4,
+	"mustLookupIPv6":     systemLookup.mustLookupIPv6,
+	"mustLookupSRV":      systemLookup.mustLookupSRV,
+	"mustLookupTXT":      systemLookup.mustLookupTXT,
+
+	// IP addresses:
+	"cidrHost":          cidrHost,
+	"cidrSubnet":        cidrSubnet,
+	"cidrSubnets":       cidrSubnets,
+	"cidrNetmask":       cidrNetmask,
+	"cidrContains":      cidrContains,
+	"cidrNormalize":     cidrNormalize,
+	"ipIsPrivate":       ipIsPrivate,
+	"ipVersion":         ipVersion,
+	"ipAdd":             ipAdd,
+	"ipNormalize":       ipNormalize,
+	"mustCidrHost":      mustCidrHost,
+	"mustCidrSubnet":    mustCidrSubnet,
+	"mustCidrSubnets":   mustCidrSubnets,
+	"mustCidrNetmask":   mustCidrNetmask,
+	"mustCidrContains":  mustCidrContains,
+	"mustCidrNormalize": mustCidrNormalize,
+	"mustIpIsPrivate":   mustIpIsPrivate,
+	"mustIpVersion":     mustIpVersion,
+	"mustIpAdd":         mustIpAdd,
+	"mustIpNormalize":   mustIpNormalize,
 
 	// Paths:
 	"base":  path.Base,
@@ -293,6 +439,26 @@ var genericMap = map[string]interface{}{
 	"b32enc": base32encode,
 	"b32dec": base32decode,
 
+	// CSV:
+	"fromCsv":               fromCsv,
+	"fromCsvRows":           fromCsvRows,
+	"fromTsv":               fromTsv,
+	"fromTsvRows":           fromTsvRows,
+	"fromDelimited":         fromDelimited,
+	"fromDelimitedRows":     fromDelimitedRows,
+	"toCsv":                 toCsv,
+	"toTsv":                 toTsv,
+	"toDelimited":           toDelimited,
+	"mustFromCsv":           mustFromCsv,
+	"mustFromCsvRows":       mustFromCsvRows,
+	"mustFromTsv":           mustFromTsv,
+	"mustFromTsvRows":       mustFromTsvRows,
+	"mustFromDelimited":     mustFromDelimited,
+	"mustFromDelimitedRows": mustFromDelimitedRows,
+	"mustToCsv":             mustToCsv,
+	"mustToTsv":             mustToTsv,
+	"mustToDelimited":       mustToDelimited,
+
 	// Data Structures:
 	"tuple":              list, // FIXME: with the addition of append/prepend these are no longer immutable.
 	"list":               list,
@@ -344,16 +510,68 @@ var genericMap = map[string]interface{}{
 	"genPrivateKey":            generatePrivateKey,
 	"derivePassword":           derivePassword,
 	"buildCustomCert":          buildCustomCertificate,
-	"genCA":                    generateCertificateAuthority,
-	"genCAWithKey":             generateCertificateAuthorityWithPEMKey,
-	"genSelfSignedCert":        generateSelfSignedCertificate,
-	"genSelfSignedCertWithKey": generateSelfSignedCertificateWithPEMKey,
-	"genSignedCert":            generateSignedCertificate,
-	"genSignedCertWithKey":     generateSignedCertificateWithPEMKey,
+	"genCA":                    systemClock.generateCertificateAuthority,
+	"genCAWithKey":             systemClock.generateCertificateAuthorityWithPEMKey,
+	"genSelfSignedCert":        systemClock.generateSelfSignedCertificate,
+	"genSelfSignedCertWithKey": systemClock.generateSelfSignedCertificateWithPEMKey,
+	"genSignedCert":            systemClock.generateSignedCertificate,
+	"genSignedCertWithKey":     systemClock.generateSignedCertificateWithPEMKey,
 	"encryptAES":               encryptAES,
 	"decryptAES":               decryptAES,
+	"encryptAESGCM":            encryptAESGCM,
+	"encryptAESGCMWithKDF":     encryptAESGCMWithKDF,
+	"decryptAESGCM":            decryptAESGCM,
+	"parseCert":                parseCert,
+	"mustParseCert":            mustParseCert,
 	"randBytes":                randBytes,
 
+	// Certificates:
+	"genCAWithOptions":             systemClock.genCAWithOptions,
+	"genSelfSignedCertWithOptions": systemClock.genSelfSignedCertWithOptions,
+	"genSignedCertWithOptions":     systemClock.genSignedCertWithOptions,
+	"genCSR":                       genCSR,
+	"signCSR":                      systemClock.signCSR,
+	"verifyCertChain":              systemClock.verifyCertChain,
+	"mustVerifyCertChain":          systemClock.mustVerifyCertChain,
+
+	// Keys:
+	"publicKeyFromPrivate": publicKeyFromPrivate,
+	"keyFingerprint":       keyFingerprint,
+	"toSSHPublicKey":       toSSHPublicKey,
+	"toOpenSSHPrivateKey":  toOpenSSHPrivateKey,
+	"toPKCS1PrivateKey":    toPKCS1PrivateKey,
+	"toPKCS8PrivateKey":    toPKCS8PrivateKey,
+
+	// Signatures:
+	"signData":            signData,
+	"verifySignature":     verifySignature,
+	"mustSignData":        mustSignData,
+	"mustVerifySignature": mustVerifySignature,
+
+	// Hashes:
+	"sha384sum":   sha384sum,
+	"sha3_256sum": sha3_256sum,
+	"blake2bsum":  blake2bsum,
+	"md5sum":      md5sum,
+	"crc32sum":    crc32sum,
+	"fnv64sum":    fnv64sum,
+	"hash":        hashData,
+	"hmacSha256":  hmacSha256,
+	"hmacSha512":  hmacSha512,
+
+	// Passwords:
+	"bcryptCost":     bcryptCost,
+	"argon2id":       argon2id,
+	"scryptHash":     scryptHash,
+	"verifyPassword": verifyPassword,
+
+	// JSON Web Tokens:
+	"jwtSign":       jwtSign,
+	"jwtDecode":     jwtDecode,
+	"jwtVerify":     systemClock.jwtVerify,
+	"mustJwtDecode": mustJwtDecode,
+	"mustJwtVerify": systemClock.mustJwtVerify,
+
 	// UUIDs:
 	"uuidv4": uuidv4,
 
@@ -380,6 +598,8 @@ var genericMap = map[string]interface{}{
 	"regexQuoteMeta":             regexQuoteMeta,
 
 	// URLs:
-	"urlParse": urlParse,
-	"urlJoin":  urlJoin,
+	"urlParse":     urlParse,
+	"urlJoin":      urlJoin,
+	"mustUrlParse": mustUrlParse,
+	"mustUrlJoin":  mustUrlJoin,
 }
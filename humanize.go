@@ -0,0 +1,203 @@
+package sprig
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Locale holds the words used by the humanize functions.
+type Locale struct {
+	// Units maps each of "year", "month", "day", "hour", "minute" and
+	// "second" to its singular and plural name.
+	Units map[string][2]string
+	// Past and Future are fmt formats for a time before and after now, such
+	// as "%s ago" and "in %s".
+	Past, Future string
+	// Now describes a time less than a second from now.
+	Now string
+	// Separator is placed between units.
+	Separator string
+}
+
+// englishLocale is the default Locale. It also supplies any words missing
+// from another Locale.
+var englishLocale = Locale{
+	Units: map[string][2]string{
+		"year":   {"year", "years"},
+		"month":  {"month", "months"},
+		"day":    {"day", "days"},
+		"hour":   {"hour", "hours"},
+		"minute": {"minute", "minutes"},
+		"second": {"second", "seconds"},
+	},
+	Past:      "%s ago",
+	Future:    "in %s",
+	Now:       "just now",
+	Separator: " ",
+}
+
+// humanizer formats durations in words using a clock and a Locale.
+type humanizer struct {
+	clock  clock
+	locale Locale
+}
+
+// systemHumanizer uses the system clock and the English locale.
+var systemHumanizer = humanizer{clock: systemClock, locale: englishLocale}
+
+// humanizeUnits are the units of the duration between two times, in the
+// order they appear.
+var humanizeUnits = []string{"year", "month", "day", "hour", "minute", "second"}
+
+// humanizeDuration describes a duration, such as "1 day 2 hours". The
+// duration can be a time.Duration or a number of nanoseconds, or a string
+// accepted by time.ParseDuration. Only the precision largest units are used,
+// counting from the first that is not zero, and the rest is dropped; a
+// precision of 0 or less uses every unit. Negative durations are described
+// like positive ones.
+func (h humanizer) humanizeDuration(precision int, duration interface{}) (string, error) {
+	var d time.Duration
+	switch v := duration.(type) {
+	case time.Duration:
+		d = v
+	case int64:
+		d = time.Duration(v)
+	case int:
+		d = time.Duration(v)
+	case string:
+		var err error
+		if d, err = time.ParseDuration(v); err != nil {
+			return "", &ParseError{Func: "humanizeDuration", Input: v, Err: err}
+		}
+	default:
+		return "", &TypeError{Func: "humanizeDuration", Arg: "duration", Got: typeName(duration), Want: "a duration, integer or duration string"}
+	}
+	if d < 0 {
+		d = -d
+	}
+	// A duration has no calendar, so it is not counted in years or months.
+	counts := []int64{
+		0,
+		0,
+		int64(d / (24 * time.Hour)),
+		int64(d % (24 * time.Hour) / time.Hour),
+		int64(d % time.Hour / time.Minute),
+		int64(d % time.Minute / time.Second),
+	}
+	return h.format(counts, precision), nil
+}
+
+// humanizeTime describes a date relative to the current time, such as
+// "3 days ago" or "in 1 month 2 days", with months and years counted on the
+// calendar. The precision is as for humanizeDuration.
+func (h humanizer) humanizeTime(precision int, date interface{}) (string, error) {
+	t, err := toTime("humanizeTime", date)
+	if err != nil {
+		return "", err
+	}
+	now := h.clock()
+	from, to, format := t, now, localeWord(h.locale.Past, englishLocale.Past)
+	if t.After(now) {
+		from, to, format = now, t, localeWord(h.locale.Future, englishLocale.Future)
+	}
+	if to.Sub(from) < time.Second {
+		return localeWord(h.locale.Now, englishLocale.Now), nil
+	}
+	return fmt.Sprintf(format, h.format(calendarDiff(from, to), precision)), nil
+}
+
+// humanizeBetween describes the time between two dates, such as "1 year
+// 2 months", with months and years counted on the calendar. The precision is
+// as for humanizeDuration.
+func (h humanizer) humanizeBetween(precision int, from interface{}, to interface{}) (string, error) {
+	f, err := toTime("humanizeBetween", from)
+	if err != nil {
+		return "", err
+	}
+	t, err := toTime("humanizeBetween", to)
+	if err != nil {
+		return "", err
+	}
+	if t.Before(f) {
+		f, t = t, f
+	}
+	return h.format(calendarDiff(f, t), precision), nil
+}
+
+// calendarDiff returns the number of years, months, days, hours, minutes and
+// seconds from one time to a later one. Months are added to from as by
+// dateAdd, so one month after January 31st is the end of February.
+func calendarDiff(from, to time.Time) []int64 {
+	to = to.In(from.Location())
+	months := (to.Year()-from.Year())*12 + int(to.Month()) - int(from.Month())
+	anchor := addMonths(from, months)
+	for months > 0 && anchor.After(to) {
+		months--
+		anchor = addMonths(from, months)
+	}
+
+	days := int(to.Sub(anchor) / (24 * time.Hour))
+	// Days can be shorter or longer than 24 hours where daylight saving time
+	// changes.
+	for !anchor.AddDate(0, 0, days+1).After(to) {
+		days++
+	}
+	for days > 0 && anchor.AddDate(0, 0, days).After(to) {
+		days--
+	}
+	rest := to.Sub(anchor.AddDate(0, 0, days))
+
+	return []int64{
+		int64(months / 12),
+		int64(months % 12),
+		int64(days),
+		int64(rest / time.Hour),
+		int64(rest % time.Hour / time.Minute),
+		int64(rest % time.Minute / time.Second),
+	}
+}
+
+// format describes the counts of humanizeUnits, using precision units from
+// the first that is not zero.
+func (h humanizer) format(counts []int64, precision int) string {
+	first := 0
+	for first < len(counts) && counts[first] == 0 {
+		first++
+	}
+	last := len(counts)
+	if precision > 0 && first+precision < last {
+		last = first + precision
+	}
+	var parts []string
+	for i := first; i < last; i++ {
+		if counts[i] != 0 {
+			parts = append(parts, h.unit(humanizeUnits[i], counts[i]))
+		}
+	}
+	if len(parts) == 0 {
+		return h.unit("second", 0)
+	}
+	return strings.Join(parts, localeWord(h.locale.Separator, englishLocale.Separator))
+}
+
+// unit returns a count followed by the singular or plural name of a unit.
+func (h humanizer) unit(name string, n int64) string {
+	names, ok := h.locale.Units[name]
+	if !ok {
+		names = englishLocale.Units[name]
+	}
+	if n == 1 {
+		return strconv.FormatInt(n, 10) + " " + names[0]
+	}
+	return strconv.FormatInt(n, 10) + " " + names[1]
+}
+
+// localeWord returns s, or the English fallback if s is empty.
+func localeWord(s, fallback string) string {
+	if s == "" {
+		return fallback
+	}
+	return s
+}
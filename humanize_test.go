@@ -0,0 +1,124 @@
+package sprig
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHumanizeDuration(t *testing.T) {
+	tests := []struct {
+		precision int
+		duration  interface{}
+		want      string
+	}{
+		{0, "26h3m5s", "1 day 2 hours 3 minutes 5 seconds"},
+		{2, "26h3m5s", "1 day 2 hours"},
+		{1, "26h3m5s", "1 day"},
+		{2, "24h5m", "1 day"},
+		{3, "24h5m", "1 day 5 minutes"},
+		{2, "-90s", "1 minute 30 seconds"},
+		{0, "500ms", "0 seconds"},
+		{0, 2 * time.Hour, "2 hours"},
+		{0, int64(time.Minute), "1 minute"},
+		{0, 9600 * time.Hour, "400 days"},
+	}
+	for _, tt := range tests {
+		got, err := systemHumanizer.humanizeDuration(tt.precision, tt.duration)
+		if assert.NoError(t, err, "%v", tt.duration) {
+			assert.Equal(t, tt.want, got, "%v", tt.duration)
+		}
+	}
+
+	_, err := systemHumanizer.humanizeDuration(0, "a while")
+	var perr *ParseError
+	assert.ErrorAs(t, err, &perr)
+	_, err = systemHumanizer.humanizeDuration(0, 1.5)
+	var terr *TypeError
+	assert.ErrorAs(t, err, &terr)
+
+	assert.NoError(t, runt(`{{ "93784s" | humanizeDuration 2 }}`, "1 day 2 hours"))
+}
+
+func TestHumanizeTime(t *testing.T) {
+	now := time.Date(2020, 3, 31, 12, 0, 0, 0, time.UTC)
+	h := humanizer{clock: func() time.Time { return now }, locale: englishLocale}
+	tests := []struct {
+		precision int
+		date      time.Time
+		want      string
+	}{
+		{0, now, "just now"},
+		{0, now.Add(-500 * time.Millisecond), "just now"},
+		{0, now.Add(-3 * 24 * time.Hour), "3 days ago"},
+		{2, now.Add(-26*time.Hour - 3*time.Minute), "1 day 2 hours ago"},
+		{0, now.Add(90 * time.Second), "in 1 minute 30 seconds"},
+		{2, time.Date(2020, 2, 29, 12, 0, 0, 0, time.UTC), "1 month 2 days ago"},
+		{1, time.Date(2020, 2, 29, 12, 0, 0, 0, time.UTC), "1 month ago"},
+		{2, time.Date(2020, 5, 2, 18, 0, 0, 0, time.UTC), "in 1 month 2 days"},
+		{1, time.Date(2018, 12, 25, 0, 0, 0, 0, time.UTC), "1 year ago"},
+		{0, time.Date(2018, 12, 25, 0, 0, 0, 0, time.UTC), "1 year 3 months 6 days 12 hours ago"},
+	}
+	for _, tt := range tests {
+		got, err := h.humanizeTime(tt.precision, tt.date)
+		if assert.NoError(t, err, "%v", tt.date) {
+			assert.Equal(t, tt.want, got, "%v", tt.date)
+		}
+	}
+
+	_, err := h.humanizeTime(0, "someday")
+	assert.Error(t, err)
+
+	fm := New(WithClock(h.clock)).TxtFuncMap()
+	assert.NoError(t, runtFuncs(fm, `{{ "2020-03-28T12:00:00Z" | humanizeTime 1 }}`, "3 days ago"))
+}
+
+func TestHumanizeBetween(t *testing.T) {
+	tests := []struct {
+		from, to string
+		want     string
+	}{
+		{"2020-01-31", "2020-02-29", "1 month"},
+		{"2020-01-30", "2020-02-28", "29 days"},
+		{"2020-01-31", "2020-03-01", "1 month 1 day"},
+		{"2019-02-28", "2020-02-29", "1 year 1 day"},
+		{"2020-02-29", "2021-02-28", "1 year"},
+		{"2021-02-28", "2020-02-29", "1 year"},
+		{"2020-01-01T00:00:00Z", "2020-01-01T00:00:00Z", "0 seconds"},
+	}
+	for _, tt := range tests {
+		got, err := systemHumanizer.humanizeBetween(0, tt.from, tt.to)
+		if assert.NoError(t, err, tt.from) {
+			assert.Equal(t, tt.want, got, "%s to %s", tt.from, tt.to)
+		}
+	}
+
+	// The day the clocks go forward in New York has 23 hours.
+	ny, err := time.LoadLocation("America/New_York")
+	if assert.NoError(t, err) {
+		got, err := systemHumanizer.humanizeBetween(0, time.Date(2020, 3, 8, 0, 0, 0, 0, ny), time.Date(2020, 3, 9, 0, 0, 0, 0, ny))
+		assert.NoError(t, err)
+		assert.Equal(t, "1 day", got)
+	}
+
+	_, err = systemHumanizer.humanizeBetween(0, "2020-01-01", "never")
+	assert.Error(t, err)
+}
+
+func TestWithLocale(t *testing.T) {
+	now := time.Date(2020, 3, 31, 12, 0, 0, 0, time.UTC)
+	fm := New(WithClock(func() time.Time { return now }), WithLocale(Locale{
+		Units: map[string][2]string{
+			"day":  {"jour", "jours"},
+			"hour": {"heure", "heures"},
+		},
+		Past:      "il y a %s",
+		Future:    "dans %s",
+		Separator: ", ",
+	})).TxtFuncMap()
+	assert.NoError(t, runtFuncs(fm, `{{ humanizeTime 0 "2020-03-30T10:00:00Z" }}`, "il y a 1 jour, 2 heures"))
+	assert.NoError(t, runtFuncs(fm, `{{ humanizeTime 0 "2020-04-02T12:00:00Z" }}`, "dans 2 jours"))
+	assert.NoError(t, runtFuncs(fm, `{{ humanizeTime 0 "2020-03-31T12:00:00Z" }}`, "just now"))
+	assert.NoError(t, runtFuncs(fm, `{{ humanizeDuration 0 "1m" }}`, "1 minute"))
+}
@@ -0,0 +1,263 @@
+package sprig
+
+import (
+	"fmt"
+	"math/big"
+	"net"
+	"net/netip"
+)
+
+// cidrHost returns the address of the given host number within a prefix,
+// ignoring errors. Negative host numbers count back from the end of the range.
+func cidrHost(prefix string, hostnum int) string {
+	s, _ := mustCidrHost(prefix, hostnum)
+	return s
+}
+
+// mustCidrHost returns the address of the given host number within a prefix,
+// returning errors.
+func mustCidrHost(prefix string, hostnum int) (string, error) {
+	p, err := parsePrefix("cidrHost", prefix)
+	if err != nil {
+		return "", err
+	}
+	size := new(big.Int).Lsh(big.NewInt(1), uint(p.Addr().BitLen()-p.Bits()))
+	n := big.NewInt(int64(hostnum))
+	if hostnum < 0 {
+		n.Add(n, size)
+	}
+	if n.Sign() < 0 || n.Cmp(size) >= 0 {
+		return "", &FuncError{Func: "cidrHost", Err: fmt.Errorf("prefix %s has no host number %d", p, hostnum)}
+	}
+	addr, _ := addAddr(p.Addr(), n)
+	return addr.String(), nil
+}
+
+// cidrSubnet returns the netnum'th subnet of a prefix extended by newbits,
+// ignoring errors.
+func cidrSubnet(prefix string, newbits int, netnum int) string {
+	s, _ := mustCidrSubnet(prefix, newbits, netnum)
+	return s
+}
+
+// mustCidrSubnet returns the netnum'th subnet of a prefix extended by newbits,
+// returning errors.
+func mustCidrSubnet(prefix string, newbits int, netnum int) (string, error) {
+	p, err := parsePrefix("cidrSubnet", prefix)
+	if err != nil {
+		return "", err
+	}
+	bits := p.Bits() + newbits
+	if newbits < 0 || bits > p.Addr().BitLen() {
+		return "", &FuncError{Func: "cidrSubnet", Err: fmt.Errorf("cannot extend prefix %s by %d bits", p, newbits)}
+	}
+	if netnum < 0 || (newbits < 63 && int64(netnum) >= int64(1)<<uint(newbits)) {
+		return "", &FuncError{Func: "cidrSubnet", Err: fmt.Errorf("prefix %s has no subnet number %d with %d new bits", p, netnum, newbits)}
+	}
+	n := new(big.Int).Lsh(big.NewInt(int64(netnum)), uint(p.Addr().BitLen()-bits))
+	addr, _ := addAddr(p.Addr(), n)
+	return netip.PrefixFrom(addr, bits).String(), nil
+}
+
+// cidrSubnets allocates consecutive subnets of a prefix, one for each of the
+// given numbers of new bits, ignoring errors.
+func cidrSubnets(prefix string, newbits ...int) []string {
+	s, _ := mustCidrSubnets(prefix, newbits...)
+	return s
+}
+
+// mustCidrSubnets allocates consecutive subnets of a prefix, one for each of
+// the given numbers of new bits, returning errors. Each subnet starts at the
+// first address after the previous one that is aligned to its size.
+func mustCidrSubnets(prefix string, newbits ...int) ([]string, error) {
+	p, err := parsePrefix("cidrSubnets", prefix)
+	if err != nil {
+		return []string{}, err
+	}
+	addrBits := p.Addr().BitLen()
+	end := new(big.Int).Lsh(big.NewInt(1), uint(addrBits-p.Bits()))
+	next := new(big.Int)
+	subnets := make([]string, 0, len(newbits))
+	for _, nb := range newbits {
+		bits := p.Bits() + nb
+		if nb < 0 || bits > addrBits {
+			return []string{}, &FuncError{Func: "cidrSubnets", Err: fmt.Errorf("cannot extend prefix %s by %d bits", p, nb)}
+		}
+		size := new(big.Int).Lsh(big.NewInt(1), uint(addrBits-bits))
+		// Round the offset up to a multiple of the subnet size.
+		next.Add(next, size)
+		next.Sub(next, big.NewInt(1))
+		next.Div(next, size)
+		next.Mul(next, size)
+		if new(big.Int).Add(next, size).Cmp(end) > 0 {
+			return []string{}, &FuncError{Func: "cidrSubnets", Err: fmt.Errorf("not enough room in prefix %s for a /%d subnet", p, bits)}
+		}
+		addr, _ := addAddr(p.Addr(), next)
+		subnets = append(subnets, netip.PrefixFrom(addr, bits).String())
+		next.Add(next, size)
+	}
+	return subnets, nil
+}
+
+// cidrNetmask returns the netmask of a prefix in address form, ignoring
+// errors.
+func cidrNetmask(prefix string) string {
+	s, _ := mustCidrNetmask(prefix)
+	return s
+}
+
+// mustCidrNetmask returns the netmask of a prefix in address form, returning
+// errors.
+func mustCidrNetmask(prefix string) (string, error) {
+	p, err := parsePrefix("cidrNetmask", prefix)
+	if err != nil {
+		return "", err
+	}
+	mask := net.CIDRMask(p.Bits(), p.Addr().BitLen())
+	addr, _ := netip.AddrFromSlice(mask)
+	return addr.String(), nil
+}
+
+// cidrContains reports whether a prefix contains an address or another
+// prefix, ignoring errors.
+func cidrContains(prefix string, ip string) bool {
+	b, _ := mustCidrContains(prefix, ip)
+	return b
+}
+
+// mustCidrContains reports whether a prefix contains an address or another
+// prefix, returning errors.
+func mustCidrContains(prefix string, ip string) (bool, error) {
+	p, err := parsePrefix("cidrContains", prefix)
+	if err != nil {
+		return false, err
+	}
+	if other, err := netip.ParsePrefix(ip); err == nil {
+		return other.Bits() >= p.Bits() && p.Contains(other.Addr()), nil
+	}
+	addr, err := parseAddr("cidrContains", ip)
+	if err != nil {
+		return false, err
+	}
+	return p.Contains(addr), nil
+}
+
+// ipIsPrivate reports whether an address is in a private range (RFC 1918 or
+// RFC 4193), ignoring errors.
+func ipIsPrivate(ip string) bool {
+	b, _ := mustIpIsPrivate(ip)
+	return b
+}
+
+// mustIpIsPrivate reports whether an address is in a private range (RFC 1918
+// or RFC 4193), returning errors.
+func mustIpIsPrivate(ip string) (bool, error) {
+	addr, err := parseAddr("ipIsPrivate", ip)
+	if err != nil {
+		return false, err
+	}
+	return addr.IsPrivate(), nil
+}
+
+// ipVersion returns 4 or 6 for an address, or 0 if it cannot be parsed.
+func ipVersion(ip string) int {
+	v, _ := mustIpVersion(ip)
+	return v
+}
+
+// mustIpVersion returns 4 or 6 for an address, returning errors.
+func mustIpVersion(ip string) (int, error) {
+	addr, err := parseAddr("ipVersion", ip)
+	if err != nil {
+		return 0, err
+	}
+	if addr.Is4() {
+		return 4, nil
+	}
+	return 6, nil
+}
+
+// ipAdd adds n to an address, ignoring errors.
+func ipAdd(ip string, n int) string {
+	s, _ := mustIpAdd(ip, n)
+	return s
+}
+
+// mustIpAdd adds n to an address, returning errors. n may be negative.
+func mustIpAdd(ip string, n int) (string, error) {
+	addr, err := parseAddr("ipAdd", ip)
+	if err != nil {
+		return "", err
+	}
+	sum, ok := addAddr(addr, big.NewInt(int64(n)))
+	if !ok {
+		return "", &FuncError{Func: "ipAdd", Err: fmt.Errorf("%s plus %d is out of range", addr, n)}
+	}
+	return sum.String(), nil
+}
+
+// ipNormalize returns the canonical form of an address, ignoring errors.
+func ipNormalize(ip string) string {
+	s, _ := mustIpNormalize(ip)
+	return s
+}
+
+// mustIpNormalize returns the canonical form of an address, returning errors.
+// IPv4-mapped IPv6 addresses are returned as IPv4 addresses, and IPv6
+// addresses are compressed as described in RFC 5952.
+func mustIpNormalize(ip string) (string, error) {
+	addr, err := parseAddr("ipNormalize", ip)
+	if err != nil {
+		return "", err
+	}
+	return addr.String(), nil
+}
+
+// cidrNormalize returns the canonical form of a prefix, ignoring errors.
+func cidrNormalize(prefix string) string {
+	s, _ := mustCidrNormalize(prefix)
+	return s
+}
+
+// mustCidrNormalize returns the canonical form of a prefix with its host bits
+// cleared, returning errors.
+func mustCidrNormalize(prefix string) (string, error) {
+	p, err := parsePrefix("cidrNormalize", prefix)
+	if err != nil {
+		return "", err
+	}
+	return p.String(), nil
+}
+
+// parseAddr parses an address, unmapping IPv4-mapped IPv6 addresses.
+func parseAddr(fn string, ip string) (netip.Addr, error) {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return netip.Addr{}, &ParseError{Func: fn, Input: ip, Err: err}
+	}
+	return addr.Unmap(), nil
+}
+
+// parsePrefix parses a prefix in CIDR notation and clears its host bits.
+func parsePrefix(fn string, prefix string) (netip.Prefix, error) {
+	p, err := netip.ParsePrefix(prefix)
+	if err != nil {
+		return netip.Prefix{}, &ParseError{Func: fn, Input: prefix, Err: err}
+	}
+	return p.Masked(), nil
+}
+
+// addAddr adds n to an address. It reports false if the result does not fit
+// in the address family.
+func addAddr(addr netip.Addr, n *big.Int) (netip.Addr, bool) {
+	sum := new(big.Int).SetBytes(addr.AsSlice())
+	sum.Add(sum, n)
+	size := addr.BitLen() / 8
+	if sum.Sign() < 0 || sum.BitLen() > addr.BitLen() {
+		return netip.Addr{}, false
+	}
+	buf := make([]byte, size)
+	sum.FillBytes(buf)
+	res, _ := netip.AddrFromSlice(buf)
+	return res.WithZone(addr.Zone()), true
+}
@@ -0,0 +1,117 @@
+package sprig
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCidrHost(t *testing.T) {
+	tests := map[string]string{
+		`{{ cidrHost "10.12.112.0/20" 16 }}`:      "10.12.112.16",
+		`{{ cidrHost "10.12.112.0/20" 268 }}`:     "10.12.113.12",
+		`{{ cidrHost "10.12.112.0/20" -1 }}`:      "10.12.127.255",
+		`{{ cidrHost "10.12.112.7/20" 1 }}`:       "10.12.112.1",
+		`{{ cidrHost "fd00:fd12:3456::/56" 16 }}`: "fd00:fd12:3456::10",
+		`{{ cidrHost "10.0.0.0/30" 4 }}`:          "",
+		`{{ cidrHost "10.0.0.0" 1 }}`:             "",
+	}
+	for tpl, expect := range tests {
+		assert.NoError(t, runt(tpl, expect))
+	}
+}
+
+func TestCidrSubnet(t *testing.T) {
+	tests := map[string]string{
+		`{{ cidrSubnet "172.16.0.0/12" 4 2 }}`:          "172.18.0.0/16",
+		`{{ cidrSubnet "10.1.2.0/24" 4 15 }}`:           "10.1.2.240/28",
+		`{{ cidrSubnet "fd00:fd12:3456::/56" 16 162 }}`: "fd00:fd12:3456:0:a200::/72",
+		`{{ cidrSubnet "10.1.2.0/24" 4 16 }}`:           "",
+		`{{ cidrSubnet "10.1.2.0/24" 9 0 }}`:            "",
+	}
+	for tpl, expect := range tests {
+		assert.NoError(t, runt(tpl, expect))
+	}
+}
+
+func TestCidrSubnets(t *testing.T) {
+	tests := map[string]string{
+		`{{ cidrSubnets "10.1.0.0/16" 4 4 8 4 }}`:       "[10.1.0.0/20 10.1.16.0/20 10.1.32.0/24 10.1.48.0/20]",
+		`{{ cidrSubnets "fd00:fd12:3456::/56" 16 16 }}`: "[fd00:fd12:3456::/72 fd00:fd12:3456:0:100::/72]",
+		`{{ cidrSubnets "10.1.0.0/24" 1 1 }}`:           "[10.1.0.0/25 10.1.0.128/25]",
+		`{{ cidrSubnets "10.1.0.0/24" 1 1 1 }}`:         "[]",
+		`{{ cidrSubnets "10.1.0.0/24" }}`:               "[]",
+	}
+	for tpl, expect := range tests {
+		assert.NoError(t, runt(tpl, expect))
+	}
+}
+
+func TestCidrNetmask(t *testing.T) {
+	tests := map[string]string{
+		`{{ cidrNetmask "172.16.0.0/12" }}`: "255.240.0.0",
+		`{{ cidrNetmask "10.0.0.0/32" }}`:   "255.255.255.255",
+		`{{ cidrNetmask "fd00::/48" }}`:     "ffff:ffff:ffff::",
+		`{{ cidrNetmask "nope" }}`:          "",
+	}
+	for tpl, expect := range tests {
+		assert.NoError(t, runt(tpl, expect))
+	}
+}
+
+func TestCidrContains(t *testing.T) {
+	tests := map[string]string{
+		`{{ cidrContains "10.0.0.0/8" "10.1.2.3" }}`:        "true",
+		`{{ cidrContains "10.0.0.0/8" "11.1.2.3" }}`:        "false",
+		`{{ cidrContains "10.0.0.0/8" "10.1.0.0/16" }}`:     "true",
+		`{{ cidrContains "10.1.0.0/16" "10.0.0.0/8" }}`:     "false",
+		`{{ cidrContains "10.0.0.0/8" "::ffff:10.0.0.1" }}`: "true",
+		`{{ cidrContains "fd00::/8" "fd12::1" }}`:           "true",
+		`{{ cidrContains "10.0.0.0/8" "nope" }}`:            "false",
+	}
+	for tpl, expect := range tests {
+		assert.NoError(t, runt(tpl, expect))
+	}
+}
+
+func TestIpFunctions(t *testing.T) {
+	tests := map[string]string{
+		`{{ ipIsPrivate "10.1.2.3" }}`:                                "true",
+		`{{ ipIsPrivate "192.168.0.1" }}`:                             "true",
+		`{{ ipIsPrivate "8.8.8.8" }}`:                                 "false",
+		`{{ ipIsPrivate "fd12::1" }}`:                                 "true",
+		`{{ ipVersion "10.1.2.3" }}`:                                  "4",
+		`{{ ipVersion "2001:db8::1" }}`:                               "6",
+		`{{ ipVersion "::ffff:10.1.2.3" }}`:                           "4",
+		`{{ ipVersion "nope" }}`:                                      "0",
+		`{{ ipAdd "10.0.0.255" 1 }}`:                                  "10.0.1.0",
+		`{{ ipAdd "10.0.1.0" -1 }}`:                                   "10.0.0.255",
+		`{{ ipAdd "2001:db8::ffff" 1 }}`:                              "2001:db8::1:0",
+		`{{ ipAdd "255.255.255.255" 1 }}`:                             "",
+		`{{ ipNormalize "2001:0DB8:0000:0000:0000:0000:0000:0001" }}`: "2001:db8::1",
+		`{{ ipNormalize "::ffff:192.0.2.1" }}`:                        "192.0.2.1",
+		`{{ ipNormalize "nope" }}`:                                    "",
+		`{{ cidrNormalize "10.1.2.3/8" }}`:                            "10.0.0.0/8",
+		`{{ cidrNormalize "2001:DB8::1/32" }}`:                        "2001:db8::/32",
+	}
+	for tpl, expect := range tests {
+		assert.NoError(t, runt(tpl, expect))
+	}
+}
+
+func TestIpErrors(t *testing.T) {
+	_, err := runRaw(`{{ mustIpAdd "nope" 1 }}`, nil)
+	var perr *ParseError
+	assert.ErrorAs(t, err, &perr)
+	assert.Equal(t, "ipAdd", perr.Func)
+	assert.Equal(t, "nope", perr.Input)
+
+	_, err = runRaw(`{{ mustCidrSubnets "10.1.0.0/24" 1 1 1 }}`, nil)
+	var ferr *FuncError
+	assert.ErrorAs(t, err, &ferr)
+	assert.Equal(t, "cidrSubnets", ferr.Func)
+
+	_, err = runRaw(`{{ mustCidrHost "10.0.0.0/30" 4 }}`, nil)
+	assert.ErrorAs(t, err, &ferr)
+	assert.Equal(t, "cidrHost", ferr.Func)
+}
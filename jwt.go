@@ -0,0 +1,161 @@
+package sprig
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// hmacAlgorithms are the JSON Web Algorithms that use a shared secret.
+var hmacAlgorithms = map[string]crypto.Hash{
+	"HS256": crypto.SHA256,
+	"HS384": crypto.SHA384,
+	"HS512": crypto.SHA512,
+}
+
+// jwtSign returns a JSON Web Token for a dict of claims. The key is the
+// shared secret for the HS algorithms and a PEM encoded private key for the
+// algorithms supported by signData.
+func jwtSign(alg string, key string, claims map[string]interface{}) (string, error) {
+	const fn = "jwtSign"
+	if _, ok := signatureAlgorithms[alg]; !ok && hmacAlgorithms[alg] == 0 {
+		return "", &FuncError{Func: fn, Err: fmt.Errorf("unknown signature algorithm %q", alg)}
+	}
+	header, err := json.Marshal(map[string]interface{}{"alg": alg, "typ": "JWT"})
+	if err != nil {
+		return "", &FuncError{Func: fn, Err: err}
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", &FuncError{Func: fn, Err: err}
+	}
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+
+	var sig []byte
+	if hash, ok := hmacAlgorithms[alg]; ok {
+		mac := hmac.New(hash.New, []byte(key))
+		mac.Write([]byte(signingInput))
+		sig = mac.Sum(nil)
+	} else {
+		priv, err := parsePrivateKeyPEM(key)
+		if err != nil {
+			return "", &ParseError{Func: fn, Input: key, Err: err}
+		}
+		if sig, err = signMessage(alg, priv, []byte(signingInput)); err != nil {
+			return "", &FuncError{Func: fn, Err: err}
+		}
+	}
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(sig), nil
+}
+
+// jwtDecode returns the header and claims of a JSON Web Token as a dict with
+// the keys header and claims, without verifying it, ignoring errors.
+func jwtDecode(token string) map[string]interface{} {
+	d, _ := mustJwtDecode(token)
+	return d
+}
+
+// mustJwtDecode returns the header and claims of a JSON Web Token as a dict
+// with the keys header and claims, without verifying it, returning errors.
+func mustJwtDecode(token string) (map[string]interface{}, error) {
+	header, claims, _, err := parseJWT("jwtDecode", token)
+	if err != nil {
+		return map[string]interface{}{}, err
+	}
+	return map[string]interface{}{"header": header, "claims": claims}, nil
+}
+
+// jwtVerify verifies a JSON Web Token and returns its header and claims like
+// jwtDecode, or an empty dict if it is not valid.
+func (c clock) jwtVerify(alg string, key string, token string) map[string]interface{} {
+	d, _ := c.mustJwtVerify(alg, key, token)
+	return d
+}
+
+// mustJwtVerify verifies a JSON Web Token and returns its header and claims
+// like jwtDecode, returning an error if it is not valid. The token must be
+// signed with the given algorithm, and the current time must be before its
+// exp claim and not before its nbf claim.
+func (c clock) mustJwtVerify(alg string, key string, token string) (map[string]interface{}, error) {
+	const fn = "jwtVerify"
+	header, claims, sig, err := parseJWT(fn, token)
+	if err != nil {
+		return map[string]interface{}{}, err
+	}
+	if header["alg"] != alg {
+		return map[string]interface{}{}, &FuncError{Func: fn, Err: fmt.Errorf("token algorithm %v does not match %s", header["alg"], alg)}
+	}
+
+	signingInput := token[:strings.LastIndex(token, ".")]
+	if hash, ok := hmacAlgorithms[alg]; ok {
+		mac := hmac.New(hash.New, []byte(key))
+		mac.Write([]byte(signingInput))
+		if !hmac.Equal(sig, mac.Sum(nil)) {
+			return map[string]interface{}{}, &FuncError{Func: fn, Err: errSignatureInvalid}
+		}
+	} else {
+		pub, err := parsePublicKey(fn, key)
+		if err != nil {
+			return map[string]interface{}{}, err
+		}
+		if err := verifyMessage(alg, pub, []byte(signingInput), sig); err != nil {
+			return map[string]interface{}{}, &FuncError{Func: fn, Err: err}
+		}
+	}
+
+	now := c()
+	if exp, ok, err := numericDate(fn, claims, "exp"); err != nil {
+		return map[string]interface{}{}, err
+	} else if ok && !now.Before(exp) {
+		return map[string]interface{}{}, &FuncError{Func: fn, Err: fmt.Errorf("token expired at %s", exp.Format(time.RFC3339))}
+	}
+	if nbf, ok, err := numericDate(fn, claims, "nbf"); err != nil {
+		return map[string]interface{}{}, err
+	} else if ok && now.Before(nbf) {
+		return map[string]interface{}{}, &FuncError{Func: fn, Err: fmt.Errorf("token is not valid before %s", nbf.Format(time.RFC3339))}
+	}
+	return map[string]interface{}{"header": header, "claims": claims}, nil
+}
+
+// parseJWT splits a JSON Web Token into its decoded header, claims and
+// signature.
+func parseJWT(fn string, token string) (map[string]interface{}, map[string]interface{}, []byte, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, nil, nil, &ParseError{Func: fn, Input: token, Err: errors.New("token must have three parts")}
+	}
+	var header, claims map[string]interface{}
+	for i, v := range []*map[string]interface{}{&header, &claims} {
+		b, err := base64.RawURLEncoding.DecodeString(parts[i])
+		if err != nil {
+			return nil, nil, nil, &ParseError{Func: fn, Input: token, Err: err}
+		}
+		if err := json.Unmarshal(b, v); err != nil {
+			return nil, nil, nil, &ParseError{Func: fn, Input: token, Err: err}
+		}
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, nil, nil, &ParseError{Func: fn, Input: token, Err: err}
+	}
+	return header, claims, sig, nil
+}
+
+// numericDate returns the time of a claim given in seconds since the epoch,
+// and whether the claim is present.
+func numericDate(fn string, claims map[string]interface{}, name string) (time.Time, bool, error) {
+	v, ok := claims[name]
+	if !ok {
+		return time.Time{}, false, nil
+	}
+	secs, ok := v.(float64)
+	if !ok {
+		return time.Time{}, false, &TypeError{Func: fn, Arg: fmt.Sprintf("claim %q", name), Got: typeName(v), Want: "number"}
+	}
+	return time.Unix(int64(secs), 0), true, nil
+}
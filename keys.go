@@ -0,0 +1,168 @@
+package sprig
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// publicKeyFromPrivate returns the PEM encoded public key of a PEM encoded
+// private key.
+func publicKeyFromPrivate(key string) (string, error) {
+	const fn = "publicKeyFromPrivate"
+	priv, err := parsePrivateKeyPEM(key)
+	if err != nil {
+		return "", &ParseError{Func: fn, Input: key, Err: err}
+	}
+	pub, err := getPublicKey(priv)
+	if err != nil {
+		return "", &FuncError{Func: fn, Err: err}
+	}
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		return "", &FuncError{Func: fn, Err: err}
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})), nil
+}
+
+// keyFingerprint returns the fingerprint of a key in the format used by
+// ssh-keygen. The algorithm is either sha256, which gives "SHA256:" followed
+// by the unpadded base64 digest, or md5, which gives the colon separated hex
+// digest.
+func keyFingerprint(algorithm string, key string) (string, error) {
+	const fn = "keyFingerprint"
+	pub, err := parseSSHPublicKey(fn, key)
+	if err != nil {
+		return "", err
+	}
+	switch strings.ToLower(algorithm) {
+	case "sha256":
+		return ssh.FingerprintSHA256(pub), nil
+	case "md5":
+		return ssh.FingerprintLegacyMD5(pub), nil
+	}
+	return "", &FuncError{Func: fn, Err: fmt.Errorf("unknown fingerprint algorithm %q", algorithm)}
+}
+
+// toSSHPublicKey returns a key in the authorized_keys format, such as
+// "ssh-ed25519 AAAA...".
+func toSSHPublicKey(key string) (string, error) {
+	pub, err := parseSSHPublicKey("toSSHPublicKey", key)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(string(ssh.MarshalAuthorizedKey(pub)), "\n"), nil
+}
+
+// toOpenSSHPrivateKey converts a PEM encoded private key to the OpenSSH
+// private key format.
+func toOpenSSHPrivateKey(key string) (string, error) {
+	const fn = "toOpenSSHPrivateKey"
+	priv, err := parsePrivateKeyPEM(key)
+	if err != nil {
+		return "", &ParseError{Func: fn, Input: key, Err: err}
+	}
+	block, err := ssh.MarshalPrivateKey(priv, "")
+	if err != nil {
+		return "", &FuncError{Func: fn, Err: err}
+	}
+	return string(pem.EncodeToMemory(block)), nil
+}
+
+// toPKCS1PrivateKey converts a PEM encoded RSA private key to PKCS#1
+// ("RSA PRIVATE KEY").
+func toPKCS1PrivateKey(key string) (string, error) {
+	const fn = "toPKCS1PrivateKey"
+	priv, err := parsePrivateKeyPEM(key)
+	if err != nil {
+		return "", &ParseError{Func: fn, Input: key, Err: err}
+	}
+	k, ok := priv.(*rsa.PrivateKey)
+	if !ok {
+		return "", &FuncError{Func: fn, Err: fmt.Errorf("PKCS#1 only supports RSA keys, got %T", priv)}
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(k)})), nil
+}
+
+// toPKCS8PrivateKey converts a PEM encoded private key to PKCS#8
+// ("PRIVATE KEY").
+func toPKCS8PrivateKey(key string) (string, error) {
+	const fn = "toPKCS8PrivateKey"
+	priv, err := parsePrivateKeyPEM(key)
+	if err != nil {
+		return "", &ParseError{Func: fn, Input: key, Err: err}
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(priv)
+	if err != nil {
+		return "", &FuncError{Func: fn, Err: err}
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})), nil
+}
+
+// parsePublicKey returns the public key of a PEM encoded private key, PEM
+// encoded public key (PKIX or PKCS#1) or certificate, or of an SSH public key
+// in the authorized_keys format.
+func parsePublicKey(fn string, key string) (crypto.PublicKey, error) {
+	block, _ := pem.Decode([]byte(key))
+	if block == nil {
+		pub, _, _, _, err := ssh.ParseAuthorizedKey([]byte(key))
+		if err != nil {
+			return nil, &ParseError{Func: fn, Input: key, Err: errors.New("no PEM data or SSH public key in input")}
+		}
+		cpub, ok := pub.(ssh.CryptoPublicKey)
+		if !ok {
+			return nil, &FuncError{Func: fn, Err: fmt.Errorf("unsupported SSH key type %s", pub.Type())}
+		}
+		return cpub.CryptoPublicKey(), nil
+	}
+
+	var pub crypto.PublicKey
+	var err error
+	switch {
+	case block.Type == "PUBLIC KEY":
+		pub, err = x509.ParsePKIXPublicKey(block.Bytes)
+	case block.Type == "RSA PUBLIC KEY":
+		pub, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	case block.Type == "CERTIFICATE":
+		var cert *x509.Certificate
+		cert, err = x509.ParseCertificate(block.Bytes)
+		if err == nil {
+			pub = cert.PublicKey
+		}
+	case strings.HasSuffix(block.Type, "PRIVATE KEY"):
+		var priv crypto.PrivateKey
+		priv, err = parsePrivateKeyPEM(key)
+		if err == nil {
+			pub, err = getPublicKey(priv)
+		}
+	default:
+		err = fmt.Errorf("no key data in PEM block of type %s", block.Type)
+	}
+	if err != nil {
+		return nil, &ParseError{Func: fn, Input: key, Err: err}
+	}
+	return pub, nil
+}
+
+// parseSSHPublicKey is like parsePublicKey, but returns an SSH public key.
+func parseSSHPublicKey(fn string, key string) (ssh.PublicKey, error) {
+	pub, err := parsePublicKey(fn, key)
+	if err != nil {
+		return nil, err
+	}
+	if k, ok := pub.(*ed25519.PublicKey); ok {
+		pub = *k
+	}
+	sshPub, err := ssh.NewPublicKey(pub)
+	if err != nil {
+		return nil, &FuncError{Func: fn, Err: err}
+	}
+	return sshPub, nil
+}
@@ -1,7 +1,6 @@
 package sprig
 
 import (
-	"fmt"
 	"math"
 	"reflect"
 	"sort"
@@ -25,7 +24,7 @@ func push(list interface{}, v interface{}) []interface{} {
 }
 
 func mustPush(list interface{}, v interface{}) ([]interface{}, error) {
-	tp := reflect.TypeOf(list).Kind()
+	tp := reflect.ValueOf(list).Kind()
 	switch tp {
 	case reflect.Slice, reflect.Array:
 		l2 := reflect.ValueOf(list)
@@ -39,7 +38,7 @@ func mustPush(list interface{}, v interface{}) ([]interface{}, error) {
 		return append(nl, v), nil
 
 	default:
-		return nil, fmt.Errorf("Cannot push on type %s", tp)
+		return nil, listTypeError("push", list)
 	}
 }
 
@@ -55,7 +54,7 @@ func prepend(list interface{}, v interface{}) []interface{} {
 func mustPrepend(list interface{}, v interface{}) ([]interface{}, error) {
 	//return append([]interface{}{v}, list...)
 
-	tp := reflect.TypeOf(list).Kind()
+	tp := reflect.ValueOf(list).Kind()
 	switch tp {
 	case reflect.Slice, reflect.Array:
 		l2 := reflect.ValueOf(list)
@@ -69,7 +68,7 @@ func mustPrepend(list interface{}, v interface{}) ([]interface{}, error) {
 		return append([]interface{}{v}, nl...), nil
 
 	default:
-		return nil, fmt.Errorf("Cannot prepend on type %s", tp)
+		return nil, listTypeError("prepend", list)
 	}
 }
 
@@ -83,7 +82,7 @@ func chunk(size int, list interface{}) [][]interface{} {
 }
 
 func mustChunk(size int, list interface{}) ([][]interface{}, error) {
-	tp := reflect.TypeOf(list).Kind()
+	tp := reflect.ValueOf(list).Kind()
 	switch tp {
 	case reflect.Slice, reflect.Array:
 		l2 := reflect.ValueOf(list)
@@ -113,7 +112,7 @@ func mustChunk(size int, list interface{}) ([][]interface{}, error) {
 		return nl, nil
 
 	default:
-		return nil, fmt.Errorf("Cannot chunk type %s", tp)
+		return nil, listTypeError("chunk", list)
 	}
 }
 
@@ -127,7 +126,7 @@ func last(list interface{}) interface{} {
 }
 
 func mustLast(list interface{}) (interface{}, error) {
-	tp := reflect.TypeOf(list).Kind()
+	tp := reflect.ValueOf(list).Kind()
 	switch tp {
 	case reflect.Slice, reflect.Array:
 		l2 := reflect.ValueOf(list)
@@ -139,7 +138,7 @@ func mustLast(list interface{}) (interface{}, error) {
 
 		return l2.Index(l - 1).Interface(), nil
 	default:
-		return nil, fmt.Errorf("Cannot find last on type %s", tp)
+		return nil, listTypeError("last", list)
 	}
 }
 
@@ -153,7 +152,7 @@ func first(list interface{}) interface{} {
 }
 
 func mustFirst(list interface{}) (interface{}, error) {
-	tp := reflect.TypeOf(list).Kind()
+	tp := reflect.ValueOf(list).Kind()
 	switch tp {
 	case reflect.Slice, reflect.Array:
 		l2 := reflect.ValueOf(list)
@@ -165,7 +164,7 @@ func mustFirst(list interface{}) (interface{}, error) {
 
 		return l2.Index(0).Interface(), nil
 	default:
-		return nil, fmt.Errorf("Cannot find first on type %s", tp)
+		return nil, listTypeError("first", list)
 	}
 }
 
@@ -179,7 +178,7 @@ func rest(list interface{}) []interface{} {
 }
 
 func mustRest(list interface{}) ([]interface{}, error) {
-	tp := reflect.TypeOf(list).Kind()
+	tp := reflect.ValueOf(list).Kind()
 	switch tp {
 	case reflect.Slice, reflect.Array:
 		l2 := reflect.ValueOf(list)
@@ -196,7 +195,7 @@ func mustRest(list interface{}) ([]interface{}, error) {
 
 		return nl, nil
 	default:
-		return nil, fmt.Errorf("Cannot find rest on type %s", tp)
+		return nil, listTypeError("rest", list)
 	}
 }
 
@@ -210,7 +209,7 @@ func initial(list interface{}) []interface{} {
 }
 
 func mustInitial(list interface{}) ([]interface{}, error) {
-	tp := reflect.TypeOf(list).Kind()
+	tp := reflect.ValueOf(list).Kind()
 	switch tp {
 	case reflect.Slice, reflect.Array:
 		l2 := reflect.ValueOf(list)
@@ -227,7 +226,7 @@ func mustInitial(list interface{}) ([]interface{}, error) {
 
 		return nl, nil
 	default:
-		return nil, fmt.Errorf("Cannot find initial on type %s", tp)
+		return nil, listTypeError("initial", list)
 	}
 }
 
@@ -253,7 +252,7 @@ func reverse(v interface{}) []interface{} {
 }
 
 func mustReverse(v interface{}) ([]interface{}, error) {
-	tp := reflect.TypeOf(v).Kind()
+	tp := reflect.ValueOf(v).Kind()
 	switch tp {
 	case reflect.Slice, reflect.Array:
 		l2 := reflect.ValueOf(v)
@@ -267,7 +266,7 @@ func mustReverse(v interface{}) ([]interface{}, error) {
 
 		return nl, nil
 	default:
-		return nil, fmt.Errorf("Cannot find reverse on type %s", tp)
+		return nil, listTypeError("reverse", v)
 	}
 }
 
@@ -281,7 +280,7 @@ func compact(list interface{}) []interface{} {
 }
 
 func mustCompact(list interface{}) ([]interface{}, error) {
-	tp := reflect.TypeOf(list).Kind()
+	tp := reflect.ValueOf(list).Kind()
 	switch tp {
 	case reflect.Slice, reflect.Array:
 		l2 := reflect.ValueOf(list)
@@ -298,7 +297,7 @@ func mustCompact(list interface{}) ([]interface{}, error) {
 
 		return nl, nil
 	default:
-		return nil, fmt.Errorf("Cannot compact on type %s", tp)
+		return nil, listTypeError("compact", list)
 	}
 }
 
@@ -312,7 +311,7 @@ func uniq(list interface{}) []interface{} {
 }
 
 func mustUniq(list interface{}) ([]interface{}, error) {
-	tp := reflect.TypeOf(list).Kind()
+	tp := reflect.ValueOf(list).Kind()
 	switch tp {
 	case reflect.Slice, reflect.Array:
 		l2 := reflect.ValueOf(list)
@@ -329,7 +328,7 @@ func mustUniq(list interface{}) ([]interface{}, error) {
 
 		return dest, nil
 	default:
-		return nil, fmt.Errorf("Cannot find uniq on type %s", tp)
+		return nil, listTypeError("uniq", list)
 	}
 }
 
@@ -352,7 +351,7 @@ func without(list interface{}, omit ...interface{}) []interface{} {
 }
 
 func mustWithout(list interface{}, omit ...interface{}) ([]interface{}, error) {
-	tp := reflect.TypeOf(list).Kind()
+	tp := reflect.ValueOf(list).Kind()
 	switch tp {
 	case reflect.Slice, reflect.Array:
 		l2 := reflect.ValueOf(list)
@@ -369,7 +368,7 @@ func mustWithout(list interface{}, omit ...interface{}) ([]interface{}, error) {
 
 		return res, nil
 	default:
-		return nil, fmt.Errorf("Cannot find without on type %s", tp)
+		return nil, listTypeError("without", list)
 	}
 }
 
@@ -386,7 +385,7 @@ func mustHas(needle interface{}, haystack interface{}) (bool, error) {
 	if haystack == nil {
 		return false, nil
 	}
-	tp := reflect.TypeOf(haystack).Kind()
+	tp := reflect.ValueOf(haystack).Kind()
 	switch tp {
 	case reflect.Slice, reflect.Array:
 		l2 := reflect.ValueOf(haystack)
@@ -401,7 +400,7 @@ func mustHas(needle interface{}, haystack interface{}) (bool, error) {
 
 		return false, nil
 	default:
-		return false, fmt.Errorf("Cannot find has on type %s", tp)
+		return false, listTypeError("has", haystack)
 	}
 }
 
@@ -420,7 +419,7 @@ func slice(list interface{}, indices ...interface{}) interface{} {
 }
 
 func mustSlice(list interface{}, indices ...interface{}) (interface{}, error) {
-	tp := reflect.TypeOf(list).Kind()
+	tp := reflect.ValueOf(list).Kind()
 	switch tp {
 	case reflect.Slice, reflect.Array:
 		l2 := reflect.ValueOf(list)
@@ -442,14 +441,14 @@ func mustSlice(list interface{}, indices ...interface{}) (interface{}, error) {
 
 		return l2.Slice(start, end).Interface(), nil
 	default:
-		return nil, fmt.Errorf("list should be type of slice or array but %s", tp)
+		return nil, listTypeError("slice", list)
 	}
 }
 
 func concat(lists ...interface{}) interface{} {
 	var res []interface{}
 	for _, list := range lists {
-		tp := reflect.TypeOf(list).Kind()
+		tp := reflect.ValueOf(list).Kind()
 		switch tp {
 		case reflect.Slice, reflect.Array:
 			l2 := reflect.ValueOf(list)
@@ -457,8 +456,13 @@ func concat(lists ...interface{}) interface{} {
 				res = append(res, l2.Index(i).Interface())
 			}
 		default:
-			panic(fmt.Sprintf("Cannot concat type %s as list", tp))
+			panic(listTypeError("concat", list))
 		}
 	}
 	return res
 }
+
+// listTypeError reports that fn was given a value that is not a list.
+func listTypeError(fn string, list interface{}) error {
+	return &TypeError{Func: fn, Arg: "list", Got: typeName(list), Want: "slice or array"}
+}
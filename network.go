@@ -1,12 +1,167 @@
 package sprig
 
 import (
+	"context"
+	"fmt"
 	"math/rand"
 	"net"
 )
 
-func getHostByName(name string) string {
-	addrs, _ := net.LookupHost(name)
-	//TODO: add error handing when release v3 comes out
-	return addrs[rand.Intn(len(addrs))]
+// Resolver looks up names in DNS. It is implemented by *net.Resolver.
+type Resolver interface {
+	LookupHost(ctx context.Context, host string) ([]string, error)
+	LookupIP(ctx context.Context, network, host string) ([]net.IP, error)
+	LookupSRV(ctx context.Context, service, proto, name string) (string, []*net.SRV, error)
+	LookupTXT(ctx context.Context, name string) ([]string, error)
+}
+
+// netLookup implements the network functions on top of a Resolver.
+type netLookup struct {
+	resolver Resolver
+	// intn picks one of several addresses.
+	intn func(n int) int
+}
+
+var systemLookup = netLookup{resolver: net.DefaultResolver, intn: rand.Intn}
+
+func (l netLookup) getHostByName(name string) string {
+	addr, _ := l.mustGetHostByName(name)
+	return addr
+}
+
+func (l netLookup) mustGetHostByName(name string) (string, error) {
+	addrs, err := l.lookupHost("getHostByName", name)
+	if err != nil {
+		return "", err
+	}
+	return addrs[l.intn(len(addrs))], nil
+}
+
+func (l netLookup) getHostsByName(name string) []string {
+	addrs, _ := l.mustGetHostsByName(name)
+	return addrs
+}
+
+func (l netLookup) mustGetHostsByName(name string) ([]string, error) {
+	return l.lookupHost("getHostsByName", name)
+}
+
+func (l netLookup) lookupHost(fn, name string) ([]string, error) {
+	addrs, err := l.resolver.LookupHost(context.Background(), name)
+	if err != nil {
+		return []string{}, &FuncError{Func: fn, Err: err}
+	}
+	if len(addrs) == 0 {
+		return []string{}, &FuncError{Func: fn, Err: fmt.Errorf("no addresses found for %q", name)}
+	}
+	return addrs, nil
+}
+
+func (l netLookup) lookupIPv4(name string) []string {
+	addrs, _ := l.mustLookupIPv4(name)
+	return addrs
+}
+
+func (l netLookup) mustLookupIPv4(name string) ([]string, error) {
+	return l.lookupIP("lookupIPv4", "ip4", name)
+}
+
+func (l netLookup) lookupIPv6(name string) []string {
+	addrs, _ := l.mustLookupIPv6(name)
+	return addrs
+}
+
+func (l netLookup) mustLookupIPv6(name string) ([]string, error) {
+	return l.lookupIP("lookupIPv6", "ip6", name)
+}
+
+func (l netLookup) lookupIP(fn, network, name string) ([]string, error) {
+	ips, err := l.resolver.LookupIP(context.Background(), network, name)
+	if err != nil {
+		return []string{}, &FuncError{Func: fn, Err: err}
+	}
+	addrs := make([]string, len(ips))
+	for i, ip := range ips {
+		addrs[i] = ip.String()
+	}
+	return addrs, nil
+}
+
+func (l netLookup) lookupSRV(service, proto, name string) []interface{} {
+	records, _ := l.mustLookupSRV(service, proto, name)
+	return records
+}
+
+// mustLookupSRV returns the SRV records for the service as dicts with the
+// keys target, port, priority and weight.
+func (l netLookup) mustLookupSRV(service, proto, name string) ([]interface{}, error) {
+	_, addrs, err := l.resolver.LookupSRV(context.Background(), service, proto, name)
+	if err != nil {
+		return []interface{}{}, &FuncError{Func: "lookupSRV", Err: err}
+	}
+	records := make([]interface{}, len(addrs))
+	for i, addr := range addrs {
+		records[i] = map[string]interface{}{
+			"target":   addr.Target,
+			"port":     int(addr.Port),
+			"priority": int(addr.Priority),
+			"weight":   int(addr.Weight),
+		}
+	}
+	return records, nil
+}
+
+func (l netLookup) lookupTXT(name string) []string {
+	records, _ := l.mustLookupTXT(name)
+	return records
+}
+
+func (l netLookup) mustLookupTXT(name string) ([]string, error) {
+	records, err := l.resolver.LookupTXT(context.Background(), name)
+	if err != nil {
+		return []string{}, &FuncError{Func: "lookupTXT", Err: err}
+	}
+	return records, nil
+}
+
+// staticResolver resolves host names from a fixed table and has no other
+// records.
+type staticResolver map[string][]string
+
+func (r staticResolver) LookupHost(ctx context.Context, host string) ([]string, error) {
+	addrs, ok := r[host]
+	if !ok {
+		return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+	}
+	return addrs, nil
+}
+
+func (r staticResolver) LookupIP(ctx context.Context, network, host string) ([]net.IP, error) {
+	addrs, err := r.LookupHost(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	var ips []net.IP
+	for _, addr := range addrs {
+		ip := net.ParseIP(addr)
+		switch {
+		case ip == nil:
+		case network == "ip4" && ip.To4() == nil:
+		case network == "ip6" && ip.To4() != nil:
+		default:
+			ips = append(ips, ip)
+		}
+	}
+	if len(ips) == 0 {
+		return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+	}
+	return ips, nil
+}
+
+func (r staticResolver) LookupSRV(ctx context.Context, service, proto, name string) (string, []*net.SRV, error) {
+	return "", nil, &net.DNSError{Err: "no such host", Name: name, IsNotFound: true}
+}
+
+func (r staticResolver) LookupTXT(ctx context.Context, name string) ([]string, error) {
+	return nil, &net.DNSError{Err: "no such host", Name: name, IsNotFound: true}
 }
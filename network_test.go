@@ -1,6 +1,7 @@
 package sprig
 
 import (
+	"context"
 	"net"
 	"testing"
 
@@ -16,3 +17,67 @@ func TestGetHostByName(t *testing.T) {
 	assert.NotNil(t, ip)
 	assert.NotEmpty(t, ip)
 }
+
+func TestGetHostByNameWithHosts(t *testing.T) {
+	fm := New(WithHosts(map[string][]string{
+		"example.test": {"192.0.2.1"},
+		"dual.test":    {"192.0.2.2", "2001:db8::2"},
+	})).TxtFuncMap()
+
+	assert.NoError(t, runtFuncs(fm, `{{ getHostByName "example.test" }}`, "192.0.2.1"))
+	assert.NoError(t, runtFuncs(fm, `{{ getHostByName "missing.test" }}`, ""))
+	assert.NoError(t, runtFuncs(fm, `{{ getHostsByName "dual.test" }}`, "[192.0.2.2 2001:db8::2]"))
+	assert.NoError(t, runtFuncs(fm, `{{ getHostsByName "missing.test" }}`, "[]"))
+	assert.NoError(t, runtFuncs(fm, `{{ lookupIPv4 "dual.test" }}`, "[192.0.2.2]"))
+	assert.NoError(t, runtFuncs(fm, `{{ lookupIPv6 "dual.test" }}`, "[2001:db8::2]"))
+	assert.NoError(t, runtFuncs(fm, `{{ lookupIPv6 "example.test" }}`, "[]"))
+	assert.NoError(t, runtFuncs(fm, `{{ mustLookupIPv4 "example.test" }}`, "[192.0.2.1]"))
+
+	_, err := runRawFuncs(fm, `{{ mustGetHostByName "missing.test" }}`)
+	var ferr *FuncError
+	assert.ErrorAs(t, err, &ferr)
+	assert.Equal(t, "getHostByName", ferr.Func)
+
+	_, err = runRawFuncs(fm, `{{ mustGetHostsByName "missing.test" }}`)
+	assert.ErrorAs(t, err, &ferr)
+	assert.Equal(t, "getHostsByName", ferr.Func)
+
+	_, err = runRawFuncs(fm, `{{ mustLookupIPv6 "example.test" }}`)
+	assert.ErrorAs(t, err, &ferr)
+	assert.Equal(t, "lookupIPv6", ferr.Func)
+
+	_, err = runRawFuncs(fm, `{{ mustLookupTXT "example.test" }}`)
+	assert.ErrorAs(t, err, &ferr)
+}
+
+type fakeResolver struct {
+	staticResolver
+}
+
+func (fakeResolver) LookupSRV(ctx context.Context, service, proto, name string) (string, []*net.SRV, error) {
+	if service != "http" || proto != "tcp" || name != "example.test" {
+		return "", nil, &net.DNSError{Err: "no such host", Name: name, IsNotFound: true}
+	}
+	return "_http._tcp.example.test.", []*net.SRV{
+		{Target: "a.example.test.", Port: 8080, Priority: 10, Weight: 5},
+		{Target: "b.example.test.", Port: 8081, Priority: 20, Weight: 0},
+	}, nil
+}
+
+func (fakeResolver) LookupTXT(ctx context.Context, name string) ([]string, error) {
+	return []string{"v=spf1 -all"}, nil
+}
+
+func TestLookupWithResolver(t *testing.T) {
+	fm := New(WithResolver(fakeResolver{})).TxtFuncMap()
+
+	tpl := `{{ range lookupSRV "http" "tcp" "example.test" }}{{ .target }}:{{ .port }} {{ .priority }} {{ .weight }};{{ end }}`
+	assert.NoError(t, runtFuncs(fm, tpl, "a.example.test.:8080 10 5;b.example.test.:8081 20 0;"))
+	assert.NoError(t, runtFuncs(fm, `{{ lookupSRV "ldap" "tcp" "example.test" }}`, "[]"))
+	assert.NoError(t, runtFuncs(fm, `{{ lookupTXT "example.test" | first }}`, "v=spf1 -all"))
+
+	_, err := runRawFuncs(fm, `{{ mustLookupSRV "ldap" "tcp" "example.test" }}`)
+	var ferr *FuncError
+	assert.ErrorAs(t, err, &ferr)
+	assert.Equal(t, "lookupSRV", ferr.Func)
+}
@@ -0,0 +1,100 @@
+package sprig
+
+import (
+	"encoding/base64"
+	"io"
+	"math/rand"
+	"sync"
+
+	util "github.com/Masterminds/goutils"
+	"github.com/google/uuid"
+	"github.com/huandu/xstrings"
+)
+
+// seededRand draws the values of the random functions from a caller-supplied
+// source instead of crypto/rand. It is safe for concurrent use.
+type seededRand struct {
+	src  *lockedSource
+	rand *rand.Rand
+}
+
+func newSeededRand(src rand.Source) *seededRand {
+	ls := &lockedSource{src: src}
+	return &seededRand{src: ls, rand: rand.New(ls)}
+}
+
+func (r *seededRand) randAlphaNumeric(count int) string {
+	s, _ := util.RandomSeed(count, 0, 0, true, true, nil, r.rand)
+	return s
+}
+
+func (r *seededRand) randAlpha(count int) string {
+	s, _ := util.RandomSeed(count, 0, 0, true, false, nil, r.rand)
+	return s
+}
+
+func (r *seededRand) randAscii(count int) string {
+	s, _ := util.RandomSeed(count, 32, 127, false, false, nil, r.rand)
+	return s
+}
+
+func (r *seededRand) randNumeric(count int) string {
+	s, _ := util.RandomSeed(count, 0, 0, false, true, nil, r.rand)
+	return s
+}
+
+func (r *seededRand) randInt(min, max int) int {
+	return r.rand.Intn(max-min) + min
+}
+
+func (r *seededRand) shuffle(str string) string {
+	return xstrings.ShuffleSource(str, r.src)
+}
+
+func (r *seededRand) randBytes(count int) (string, error) {
+	buf := make([]byte, count)
+	if _, err := io.ReadFull(r.src, buf); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(buf), nil
+}
+
+func (r *seededRand) uuidv4() string {
+	id, err := uuid.NewRandomFromReader(r.src)
+	if err != nil {
+		return ""
+	}
+	return id.String()
+}
+
+// lockedSource makes a rand.Source safe for concurrent template execution.
+// It also reads random bytes from the source.
+type lockedSource struct {
+	mu  sync.Mutex
+	src rand.Source
+}
+
+func (s *lockedSource) Int63() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.src.Int63()
+}
+
+func (s *lockedSource) Seed(seed int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.src.Seed(seed)
+}
+
+func (s *lockedSource) Read(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := 0; i < len(p); i += 7 {
+		v := s.src.Int63()
+		for j := i; j < i+7 && j < len(p); j++ {
+			p[j] = byte(v)
+			v >>= 8
+		}
+	}
+	return len(p), nil
+}
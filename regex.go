@@ -10,7 +10,11 @@ func regexMatch(regex string, s string) bool {
 }
 
 func mustRegexMatch(regex string, s string) (bool, error) {
-	return regexp.MatchString(regex, s)
+	r, err := regexp.Compile(regex)
+	if err != nil {
+		return false, &ParseError{Func: "regexMatch", Input: regex, Err: err}
+	}
+	return r.MatchString(s), nil
 }
 
 func regexFindAll(regex string, s string, n int) []string {
@@ -21,7 +25,7 @@ func regexFindAll(regex string, s string, n int) []string {
 func mustRegexFindAll(regex string, s string, n int) ([]string, error) {
 	r, err := regexp.Compile(regex)
 	if err != nil {
-		return []string{}, err
+		return []string{}, &ParseError{Func: "regexFindAll", Input: regex, Err: err}
 	}
 	return r.FindAllString(s, n), nil
 }
@@ -34,7 +38,7 @@ func regexFind(regex string, s string) string {
 func mustRegexFind(regex string, s string) (string, error) {
 	r, err := regexp.Compile(regex)
 	if err != nil {
-		return "", err
+		return "", &ParseError{Func: "regexFind", Input: regex, Err: err}
 	}
 	return r.FindString(s), nil
 }
@@ -47,7 +51,7 @@ func regexReplaceAll(regex string, s string, repl string) string {
 func mustRegexReplaceAll(regex string, s string, repl string) (string, error) {
 	r, err := regexp.Compile(regex)
 	if err != nil {
-		return "", err
+		return "", &ParseError{Func: "regexReplaceAll", Input: regex, Err: err}
 	}
 	return r.ReplaceAllString(s, repl), nil
 }
@@ -60,7 +64,7 @@ func regexReplaceAllLiteral(regex string, s string, repl string) string {
 func mustRegexReplaceAllLiteral(regex string, s string, repl string) (string, error) {
 	r, err := regexp.Compile(regex)
 	if err != nil {
-		return "", err
+		return "", &ParseError{Func: "regexReplaceAllLiteral", Input: regex, Err: err}
 	}
 	return r.ReplaceAllLiteralString(s, repl), nil
 }
@@ -73,7 +77,7 @@ func regexSplit(regex string, s string, n int) []string {
 func mustRegexSplit(regex string, s string, n int) ([]string, error) {
 	r, err := regexp.Compile(regex)
 	if err != nil {
-		return []string{}, err
+		return []string{}, &ParseError{Func: "regexSplit", Input: regex, Err: err}
 	}
 	return r.Split(s, n), nil
 }
@@ -0,0 +1,282 @@
+package sprig
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// FunctionInfo describes a template function.
+type FunctionInfo struct {
+	// Name is the name the function is registered under.
+	Name string
+	// Category is the group the function belongs to.
+	Category Category
+	// Aliases lists the other names bound to the same function.
+	Aliases []string
+	// Deprecated is true if another name or function should be used instead.
+	Deprecated bool
+	// Hermetic is true if the function always returns the same result for
+	// the same input.
+	Hermetic bool
+	// Must is the name of the counterpart that returns an error instead of
+	// swallowing it, if there is one.
+	Must string
+	// Signature is the Go signature of the function, e.g.
+	// "func(string, string) bool".
+	Signature string
+}
+
+// Functions returns descriptors for all functions in GenericFuncMap, sorted
+// by name.
+func Functions() []FunctionInfo {
+	infos := make([]FunctionInfo, 0, len(genericMap))
+	for name := range genericMap {
+		info, _ := LookupFunction(name)
+		infos = append(infos, info)
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+	return infos
+}
+
+// LookupFunction returns the descriptor for the named function. It returns
+// false if there is no such function.
+func LookupFunction(name string) (FunctionInfo, bool) {
+	fn, ok := genericMap[name]
+	if !ok {
+		return FunctionInfo{}, false
+	}
+
+	info := FunctionInfo{
+		Name:       name,
+		Category:   functionCategory[name],
+		Aliases:    functionAliases[name],
+		Deprecated: deprecatedFunctions[name],
+		Hermetic:   true,
+		Signature:  reflect.TypeOf(fn).String(),
+	}
+	for _, n := range nonhermeticFunctions {
+		if n == name {
+			info.Hermetic = false
+			break
+		}
+	}
+	info.Must = mustCounterpart(name)
+	for _, alias := range info.Aliases {
+		if info.Must != "" {
+			break
+		}
+		info.Must = mustCounterpart(alias)
+	}
+	return info, true
+}
+
+// mustCounterpart returns the name of the must* variant of a function, or ""
+// if it has none.
+func mustCounterpart(name string) string {
+	if strings.HasPrefix(name, "must") {
+		return ""
+	}
+	for _, candidate := range []string{
+		"must" + strings.ToUpper(name[:1]) + name[1:],
+		"must_" + name,
+	} {
+		if _, ok := genericMap[candidate]; ok {
+			return candidate
+		}
+	}
+	return ""
+}
+
+// Category groups related template functions so that they can be selected
+// together when building a function map.
+type Category string
+
+// Function categories. These mirror the sections of the function
+// documentation.
+const (
+	CategoryDate        Category = "date"
+	CategoryStrings     Category = "strings"
+	CategoryConversion  Category = "conversion"
+	CategoryMath        Category = "math"
+	CategoryDefaults    Category = "defaults"
+	CategoryEncoding    Category = "encoding"
+	CategoryLists       Category = "lists"
+	CategoryDicts       Category = "dicts"
+	CategoryReflection  Category = "reflection"
+	CategoryPaths       Category = "paths"
+	CategoryOS          Category = "os"
+	CategoryNetwork     Category = "network"
+	CategoryCrypto      Category = "crypto"
+	CategoryUUID        Category = "uuid"
+	CategorySemver      Category = "semver"
+	CategoryFlowControl Category = "flow_control"
+	CategoryRegex       Category = "regex"
+	CategoryURL         Category = "url"
+)
+
+// functionCategories lists the functions in genericMap by category. Every
+// function must appear exactly once.
+var functionCategories = map[Category][]string{
+	CategoryDate: {
+		"ago", "date", "date_in_zone", "date_modify", "dateInZone", "dateModify",
+		"duration", "durationRound", "htmlDate", "htmlDateInZone",
+		"must_date_modify", "mustDateModify", "mustToDate", "now", "toDate",
+		"unixEpoch", "parseDate", "mustParseDate", "mustAgo", "mustDate",
+		"mustDateInZone", "mustHtmlDate", "mustHtmlDateInZone", "dateFormat",
+		"strftime", "strptime", "mustDateFormat", "mustStrftime", "mustStrptime",
+		"dateAdd", "mustDateAdd", "startOf", "endOf", "dateTruncate", "weekday",
+		"isoWeek", "dayOfYear", "daysBetween", "cronValid", "cronNext", "cronPrev",
+		"cronDescribe", "humanizeDuration", "humanizeTime", "humanizeBetween",
+	},
+	CategoryStrings: {
+		"hello", "abbrev", "abbrevboth", "trunc", "trim", "upper", "lower",
+		"title", "untitle", "substr", "repeat", "trimall", "trimAll",
+		"trimSuffix", "trimPrefix", "nospace", "initials", "randAlphaNum",
+		"randAlpha", "randAscii", "randNumeric", "swapcase", "shuffle",
+		"snakecase", "camelcase", "kebabcase", "wrap", "wrapWith", "contains",
+		"hasPrefix", "hasSuffix", "quote", "squote", "cat", "indent", "nindent",
+		"replace", "plural", "split", "splitList", "splitn", "toStrings", "join",
+		"sortAlpha",
+	},
+	CategoryConversion: {
+		"toString", "atoi", "int64", "int", "float64", "toDecimal",
+	},
+	CategoryMath: {
+		"seq", "until", "untilStep", "add1", "add", "sub", "div", "mod", "mul",
+		"randInt", "add1f", "addf", "subf", "divf", "mulf", "biggest", "max",
+		"min", "maxf", "minf", "ceil", "floor", "round",
+	},
+	CategoryDefaults: {
+		"default", "empty", "coalesce", "all", "any", "fromJson", "toJson",
+		"toPrettyJson", "toRawJson", "mustFromJson", "mustToJson",
+		"mustToPrettyJson", "mustToRawJson", "fromYaml", "fromYamlArray",
+		"fromYamlAll", "toYaml", "mustFromYaml", "mustFromYamlArray",
+		"mustFromYamlAll", "mustToYaml", "fromToml", "toToml", "mustFromToml",
+		"mustToToml", "ternary",
+	},
+	CategoryEncoding: {
+		"b64enc", "b64dec", "b32enc", "b32dec", "fromCsv", "fromCsvRows",
+		"fromTsv", "fromTsvRows", "fromDelimited", "fromDelimitedRows", "toCsv",
+		"toTsv", "toDelimited", "mustFromCsv", "mustFromCsvRows", "mustFromTsv",
+		"mustFromTsvRows", "mustFromDelimited", "mustFromDelimitedRows",
+		"mustToCsv", "mustToTsv", "mustToDelimited",
+	},
+	CategoryLists: {
+		"tuple", "list", "append", "push", "mustAppend", "mustPush", "prepend",
+		"mustPrepend", "first", "mustFirst", "rest", "mustRest", "last",
+		"mustLast", "initial", "mustInitial", "reverse", "mustReverse", "uniq",
+		"mustUniq", "without", "mustWithout", "has", "mustHas", "slice",
+		"mustSlice", "concat", "chunk", "mustChunk", "compact", "mustCompact",
+	},
+	CategoryDicts: {
+		"dict", "get", "set", "unset", "hasKey", "pluck", "keys", "pick", "omit",
+		"merge", "mergeOverwrite", "mustMerge", "mustMergeOverwrite", "values",
+		"dig", "deepCopy", "mustDeepCopy",
+	},
+	CategoryReflection: {
+		"typeOf", "typeIs", "typeIsLike", "kindOf", "kindIs", "deepEqual",
+	},
+	CategoryPaths: {
+		"base", "dir", "clean", "ext", "isAbs",
+		"osBase", "osClean", "osDir", "osExt", "osIsAbs",
+	},
+	CategoryOS: {
+		"env", "expandenv",
+	},
+	CategoryNetwork: {
+		"getHostByName", "getHostsByName", "lookupIPv4", "lookupIPv6",
+		"lookupSRV", "lookupTXT", "mustGetHostByName", "mustGetHostsByName",
+		"mustLookupIPv4", "mustLookupIPv6", "mustLookupSRV", "mustLookupTXT",
+		"cidrHost", "cidrSubnet", "cidrSubnets", "cidrNetmask", "cidrContains",
+		"cidrNormalize", "ipIsPrivate", "ipVersion", "ipAdd", "ipNormalize",
+		"mustCidrHost", "mustCidrSubnet", "mustCidrSubnets", "mustCidrNetmask",
+		"mustCidrContains", "mustCidrNormalize", "mustIpIsPrivate",
+		"mustIpVersion", "mustIpAdd", "mustIpNormalize",
+	},
+	CategoryCrypto: {
+		"sha1sum", "sha256sum", "sha512sum", "adler32sum", "bcrypt", "htpasswd",
+		"genPrivateKey", "derivePassword", "buildCustomCert", "genCA",
+		"genCAWithKey", "genSelfSignedCert", "genSelfSignedCertWithKey",
+		"genSignedCert", "genSignedCertWithKey", "encryptAES", "decryptAES",
+		"encryptAESGCM", "encryptAESGCMWithKDF", "decryptAESGCM",
+		"parseCert", "mustParseCert", "genCAWithOptions",
+		"genSelfSignedCertWithOptions", "genSignedCertWithOptions", "genCSR",
+		"signCSR", "verifyCertChain", "mustVerifyCertChain",
+		"publicKeyFromPrivate", "keyFingerprint", "toSSHPublicKey",
+		"toOpenSSHPrivateKey", "toPKCS1PrivateKey", "toPKCS8PrivateKey",
+		"signData", "verifySignature", "mustSignData", "mustVerifySignature",
+		"jwtSign", "jwtDecode", "jwtVerify", "mustJwtDecode", "mustJwtVerify",
+		"sha384sum", "sha3_256sum", "blake2bsum", "md5sum", "crc32sum",
+		"fnv64sum", "hash", "hmacSha256", "hmacSha512", "bcryptCost",
+		"argon2id", "scryptHash", "verifyPassword",
+		"randBytes",
+	},
+	CategoryUUID: {
+		"uuidv4",
+	},
+	CategorySemver: {
+		"semver", "semverCompare",
+	},
+	CategoryFlowControl: {
+		"fail",
+	},
+	CategoryRegex: {
+		"regexMatch", "mustRegexMatch", "regexFindAll", "mustRegexFindAll",
+		"regexFind", "mustRegexFind", "regexReplaceAll", "mustRegexReplaceAll",
+		"regexReplaceAllLiteral", "mustRegexReplaceAllLiteral", "regexSplit",
+		"mustRegexSplit", "regexQuoteMeta",
+	},
+	CategoryURL: {
+		"urlParse", "urlJoin", "mustUrlParse", "mustUrlJoin",
+	},
+}
+
+// functionCategory maps a function name to its category.
+var functionCategory = func() map[string]Category {
+	m := make(map[string]Category, len(genericMap))
+	for cat, names := range functionCategories {
+		for _, name := range names {
+			m[name] = cat
+		}
+	}
+	return m
+}()
+
+// aliasGroups lists sets of names that are bound to the same function.
+var aliasGroups = [][]string{
+	{"date_in_zone", "dateInZone"},
+	{"date_modify", "dateModify"},
+	{"must_date_modify", "mustDateModify"},
+	{"strftime", "dateFormat"},
+	{"mustStrftime", "mustDateFormat"},
+	{"trimall", "trimAll"},
+	{"biggest", "max"},
+	{"tuple", "list"},
+	{"append", "push"},
+	{"mustAppend", "mustPush"},
+}
+
+// functionAliases maps a function name to its other names.
+var functionAliases = func() map[string][]string {
+	m := map[string][]string{}
+	for _, group := range aliasGroups {
+		for _, name := range group {
+			for _, alias := range group {
+				if alias != name {
+					m[name] = append(m[name], alias)
+				}
+			}
+		}
+	}
+	return m
+}()
+
+// deprecatedFunctions are kept for compatibility. Templates should use the
+// alias instead.
+var deprecatedFunctions = map[string]bool{
+	"date_in_zone":     true,
+	"date_modify":      true,
+	"must_date_modify": true,
+	"trimall":          true,
+}
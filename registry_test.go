@@ -0,0 +1,85 @@
+package sprig
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFunctionCategories(t *testing.T) {
+	seen := map[string]Category{}
+	for cat, names := range functionCategories {
+		for _, name := range names {
+			prev, dup := seen[name]
+			assert.False(t, dup, "%s is listed in %s and %s", name, prev, cat)
+			seen[name] = cat
+			_, ok := genericMap[name]
+			assert.True(t, ok, "%s is categorized but not defined", name)
+		}
+	}
+	for name := range genericMap {
+		_, ok := seen[name]
+		assert.True(t, ok, "%s has no category", name)
+	}
+}
+
+func TestFunctions(t *testing.T) {
+	infos := Functions()
+	assert.Equal(t, len(genericMap), len(infos))
+	for i := 1; i < len(infos); i++ {
+		assert.True(t, infos[i-1].Name < infos[i].Name)
+	}
+}
+
+func TestLookupFunction(t *testing.T) {
+	_, ok := LookupFunction("nope")
+	assert.False(t, ok)
+
+	info, ok := LookupFunction("date_modify")
+	assert.True(t, ok)
+	assert.Equal(t, FunctionInfo{
+		Name:       "date_modify",
+		Category:   CategoryDate,
+		Aliases:    []string{"dateModify"},
+		Deprecated: true,
+		Hermetic:   false,
+		Must:       "must_date_modify",
+		Signature:  "func(string, interface {}) time.Time",
+	}, info)
+
+	info, _ = LookupFunction("first")
+	assert.Equal(t, CategoryLists, info.Category)
+	assert.Equal(t, "mustFirst", info.Must)
+	assert.True(t, info.Hermetic)
+	assert.False(t, info.Deprecated)
+	assert.Equal(t, "func(interface {}) interface {}", info.Signature)
+
+	info, _ = LookupFunction("mustPush")
+	assert.Equal(t, []string{"mustAppend"}, info.Aliases)
+	assert.Equal(t, "", info.Must)
+
+	info, _ = LookupFunction("append")
+	assert.Equal(t, "mustAppend", info.Must)
+}
+
+func TestLookupFunctionNonhermetic(t *testing.T) {
+	hermetic := New(Hermetic()).GenericFuncMap()
+	for _, name := range []string{
+		// Clock
+		"ago", "mustAgo", "durationRound", "strftime", "mustStrftime",
+		"dateFormat", "mustDateFormat",
+		// Random source
+		"randInt", "shuffle",
+		// Random salt, nonce, IV or key
+		"argon2id", "bcrypt", "htpasswd", "encryptAES", "encryptAESGCM",
+		"genPrivateKey", "genCA", "genCAWithKey", "genSelfSignedCert",
+		"genSelfSignedCertWithKey", "genSignedCert", "genSignedCertWithKey",
+		"genCSR", "signCSR", "signData", "mustSignData",
+		"jwtSign",
+	} {
+		info, ok := LookupFunction(name)
+		assert.True(t, ok, name)
+		assert.False(t, info.Hermetic, name)
+		assert.NotContains(t, hermetic, name)
+	}
+}
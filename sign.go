@@ -0,0 +1,181 @@
+package sprig
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// signatureAlgorithm describes one of the JSON Web Algorithms supported by
+// signData and verifySignature.
+type signatureAlgorithm struct {
+	hash crypto.Hash
+	// kind is "rsa", "pss", "ecdsa" or "eddsa".
+	kind  string
+	curve elliptic.Curve
+}
+
+var signatureAlgorithms = map[string]signatureAlgorithm{
+	"RS256": {crypto.SHA256, "rsa", nil},
+	"RS384": {crypto.SHA384, "rsa", nil},
+	"RS512": {crypto.SHA512, "rsa", nil},
+	"PS256": {crypto.SHA256, "pss", nil},
+	"PS384": {crypto.SHA384, "pss", nil},
+	"PS512": {crypto.SHA512, "pss", nil},
+	"ES256": {crypto.SHA256, "ecdsa", elliptic.P256()},
+	"ES384": {crypto.SHA384, "ecdsa", elliptic.P384()},
+	"ES512": {crypto.SHA512, "ecdsa", elliptic.P521()},
+	"EdDSA": {0, "eddsa", nil},
+}
+
+// errSignatureInvalid is returned when a signature does not match.
+var errSignatureInvalid = errors.New("signature verification failed")
+
+// signData signs a message with a PEM encoded private key and returns the
+// base64 encoded signature, ignoring errors.
+func signData(alg string, key string, message string) string {
+	sig, _ := mustSignData(alg, key, message)
+	return sig
+}
+
+// mustSignData signs a message with a PEM encoded private key and returns the
+// base64 encoded signature, returning errors.
+func mustSignData(alg string, key string, message string) (string, error) {
+	const fn = "signData"
+	priv, err := parsePrivateKeyPEM(key)
+	if err != nil {
+		return "", &ParseError{Func: fn, Input: key, Err: err}
+	}
+	sig, err := signMessage(alg, priv, []byte(message))
+	if err != nil {
+		return "", &FuncError{Func: fn, Err: err}
+	}
+	return base64.StdEncoding.EncodeToString(sig), nil
+}
+
+// verifySignature reports whether a base64 encoded signature of a message is
+// valid for a key, ignoring errors.
+func verifySignature(alg string, key string, signature string, message string) bool {
+	ok, _ := mustVerifySignature(alg, key, signature, message)
+	return ok
+}
+
+// mustVerifySignature reports whether a base64 encoded signature of a message
+// is valid for a key, returning an error if it is not. The key may be a
+// private key, public key or certificate in PEM format.
+func mustVerifySignature(alg string, key string, signature string, message string) (bool, error) {
+	const fn = "verifySignature"
+	pub, err := parsePublicKey(fn, key)
+	if err != nil {
+		return false, err
+	}
+	sig, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return false, &ParseError{Func: fn, Input: signature, Err: err}
+	}
+	if err := verifyMessage(alg, pub, []byte(message), sig); err != nil {
+		return false, &FuncError{Func: fn, Err: err}
+	}
+	return true, nil
+}
+
+// signMessage signs a message with the named algorithm. ECDSA signatures are
+// the concatenated r and s values, as in JSON Web Signatures.
+func signMessage(alg string, priv crypto.PrivateKey, message []byte) ([]byte, error) {
+	sa, ok := signatureAlgorithms[alg]
+	if !ok {
+		return nil, fmt.Errorf("unknown signature algorithm %q", alg)
+	}
+	digest := message
+	if sa.hash != 0 {
+		h := sa.hash.New()
+		h.Write(message)
+		digest = h.Sum(nil)
+	}
+
+	switch k := priv.(type) {
+	case *rsa.PrivateKey:
+		switch sa.kind {
+		case "rsa":
+			return rsa.SignPKCS1v15(rand.Reader, k, sa.hash, digest)
+		case "pss":
+			return rsa.SignPSS(rand.Reader, k, sa.hash, digest, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash})
+		}
+	case *ecdsa.PrivateKey:
+		if sa.kind == "ecdsa" && k.Curve == sa.curve {
+			r, s, err := ecdsa.Sign(rand.Reader, k, digest)
+			if err != nil {
+				return nil, err
+			}
+			size := (k.Curve.Params().BitSize + 7) / 8
+			sig := make([]byte, 2*size)
+			r.FillBytes(sig[:size])
+			s.FillBytes(sig[size:])
+			return sig, nil
+		}
+	case ed25519.PrivateKey:
+		if sa.kind == "eddsa" {
+			return ed25519.Sign(k, message), nil
+		}
+	}
+	return nil, fmt.Errorf("%s cannot be used with a %T key", alg, priv)
+}
+
+// verifyMessage checks a signature made by signMessage.
+func verifyMessage(alg string, pub crypto.PublicKey, message []byte, sig []byte) error {
+	sa, ok := signatureAlgorithms[alg]
+	if !ok {
+		return fmt.Errorf("unknown signature algorithm %q", alg)
+	}
+	digest := message
+	if sa.hash != 0 {
+		h := sa.hash.New()
+		h.Write(message)
+		digest = h.Sum(nil)
+	}
+
+	switch k := pub.(type) {
+	case *rsa.PublicKey:
+		var err error
+		switch sa.kind {
+		case "rsa":
+			err = rsa.VerifyPKCS1v15(k, sa.hash, digest, sig)
+		case "pss":
+			err = rsa.VerifyPSS(k, sa.hash, digest, sig, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthAuto})
+		default:
+			return fmt.Errorf("%s cannot be used with a %T key", alg, pub)
+		}
+		if err != nil {
+			return errSignatureInvalid
+		}
+		return nil
+	case *ecdsa.PublicKey:
+		if sa.kind == "ecdsa" && k.Curve == sa.curve {
+			size := (k.Curve.Params().BitSize + 7) / 8
+			if len(sig) != 2*size {
+				return errSignatureInvalid
+			}
+			r := new(big.Int).SetBytes(sig[:size])
+			s := new(big.Int).SetBytes(sig[size:])
+			if !ecdsa.Verify(k, digest, r, s) {
+				return errSignatureInvalid
+			}
+			return nil
+		}
+	case ed25519.PublicKey:
+		if sa.kind == "eddsa" {
+			if !ed25519.Verify(k, message, sig) {
+				return errSignatureInvalid
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("%s cannot be used with a %T key", alg, pub)
+}
@@ -0,0 +1,54 @@
+package sprig
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSignAndVerify(t *testing.T) {
+	keys := map[string]string{
+		"rsa":     generatePrivateKey("rsa"),
+		"ecdsa":   generatePrivateKey("ecdsa"),
+		"ed25519": generatePrivateKey("ed25519"),
+	}
+	tests := map[string]string{
+		"RS256": "rsa",
+		"RS512": "rsa",
+		"PS256": "rsa",
+		"ES256": "ecdsa",
+		"EdDSA": "ed25519",
+	}
+	for alg, typ := range tests {
+		priv := keys[typ]
+		pub, err := publicKeyFromPrivate(priv)
+		assert.NoError(t, err)
+
+		sig, err := mustSignData(alg, priv, "hello")
+		assert.NoError(t, err, alg)
+
+		ok, err := mustVerifySignature(alg, pub, sig, "hello")
+		assert.NoError(t, err, alg)
+		assert.True(t, ok, alg)
+		assert.True(t, verifySignature(alg, priv, sig, "hello"), alg)
+
+		ok, err = mustVerifySignature(alg, pub, sig, "hellO")
+		assert.False(t, ok, alg)
+		assert.EqualError(t, err, "verifySignature: signature verification failed", alg)
+	}
+
+	_, err := mustSignData("ES384", keys["ecdsa"], "hello")
+	assert.EqualError(t, err, "signData: ES384 cannot be used with a *ecdsa.PrivateKey key")
+	_, err = mustSignData("HS256", keys["rsa"], "hello")
+	assert.EqualError(t, err, `signData: unknown signature algorithm "HS256"`)
+	_, err = mustVerifySignature("RS256", keys["ecdsa"], "AAAA", "hello")
+	assert.EqualError(t, err, "verifySignature: RS256 cannot be used with a *ecdsa.PublicKey key")
+	_, err = mustVerifySignature("EdDSA", keys["ed25519"], "not base64!", "hello")
+	var perr *ParseError
+	assert.ErrorAs(t, err, &perr)
+	assert.Equal(t, "", signData("RS256", "not a key", "hello"))
+
+	tpl := fmt.Sprintf(`{{ $sig := "hello" | signData "EdDSA" %q }}{{ "hello" | verifySignature "EdDSA" %q $sig }}`, keys["ed25519"], keys["ed25519"])
+	assert.NoError(t, runt(tpl, "true"))
+}
@@ -0,0 +1,278 @@
+package sprig
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// datePresets are the named formats accepted by strftime and strptime in
+// place of a pattern.
+var datePresets = map[string]string{
+	"ansic":       time.ANSIC,
+	"dateonly":    "2006-01-02",
+	"datetime":    "2006-01-02 15:04:05",
+	"iso8601":     "2006-01-02T15:04:05Z07:00",
+	"kitchen":     time.Kitchen,
+	"rfc1123":     time.RFC1123,
+	"rfc1123z":    time.RFC1123Z,
+	"rfc3339":     time.RFC3339,
+	"rfc3339nano": time.RFC3339Nano,
+	"rfc822":      time.RFC822,
+	"rfc822z":     time.RFC822Z,
+	"rfc850":      time.RFC850,
+	"rubydate":    time.RubyDate,
+	"stamp":       time.Stamp,
+	"timeonly":    "15:04:05",
+	"unixdate":    time.UnixDate,
+}
+
+// strftime formats a date with a strftime pattern such as "%Y-%m-%d %H:%M:%S"
+// or one of the datePresets. The date can be anything parseDate accepts and
+// is formatted in its own time zone; if it cannot be converted, the current
+// time is used instead. Unknown directives are copied to the output.
+func (c clock) strftime(format string, date interface{}) string {
+	s, _ := formatStrftime("strftime", format, c.timeOrNow(date))
+	return s
+}
+
+// mustStrftime is like strftime, but returns an error if the date cannot be
+// converted or the pattern has an unknown directive.
+func (c clock) mustStrftime(format string, date interface{}) (string, error) {
+	t, err := toTime("strftime", date)
+	if err != nil {
+		return "", err
+	}
+	return formatStrftime("strftime", format, t)
+}
+
+// formatStrftime formats t with a strftime pattern or preset. It returns the
+// formatted string along with an error for the first unknown directive.
+func formatStrftime(fn string, format string, t time.Time) (string, error) {
+	if layout, ok := datePresets[strings.ToLower(format)]; ok {
+		return t.Format(layout), nil
+	}
+
+	var b strings.Builder
+	var err error
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' || i == len(format)-1 {
+			b.WriteByte(format[i])
+			continue
+		}
+		i++
+		switch format[i] {
+		case 'a':
+			b.WriteString(t.Format("Mon"))
+		case 'A':
+			b.WriteString(t.Format("Monday"))
+		case 'b', 'h':
+			b.WriteString(t.Format("Jan"))
+		case 'B':
+			b.WriteString(t.Format("January"))
+		case 'c':
+			b.WriteString(t.Format("Mon Jan _2 15:04:05 2006"))
+		case 'C':
+			fmt.Fprintf(&b, "%02d", t.Year()/100)
+		case 'd':
+			fmt.Fprintf(&b, "%02d", t.Day())
+		case 'D', 'x':
+			b.WriteString(t.Format("01/02/06"))
+		case 'e':
+			fmt.Fprintf(&b, "%2d", t.Day())
+		case 'f':
+			fmt.Fprintf(&b, "%06d", t.Nanosecond()/1000)
+		case 'F':
+			b.WriteString(t.Format("2006-01-02"))
+		case 'g':
+			year, _ := t.ISOWeek()
+			fmt.Fprintf(&b, "%02d", year%100)
+		case 'G':
+			year, _ := t.ISOWeek()
+			fmt.Fprintf(&b, "%d", year)
+		case 'H':
+			fmt.Fprintf(&b, "%02d", t.Hour())
+		case 'I':
+			b.WriteString(t.Format("03"))
+		case 'j':
+			fmt.Fprintf(&b, "%03d", t.YearDay())
+		case 'k':
+			fmt.Fprintf(&b, "%2d", t.Hour())
+		case 'l':
+			fmt.Fprintf(&b, "%2d", (t.Hour()+11)%12+1)
+		case 'm':
+			fmt.Fprintf(&b, "%02d", int(t.Month()))
+		case 'M':
+			fmt.Fprintf(&b, "%02d", t.Minute())
+		case 'n':
+			b.WriteByte('\n')
+		case 'p':
+			b.WriteString(t.Format("PM"))
+		case 'P':
+			b.WriteString(t.Format("pm"))
+		case 'r':
+			b.WriteString(t.Format("03:04:05 PM"))
+		case 'R':
+			b.WriteString(t.Format("15:04"))
+		case 's':
+			b.WriteString(strconv.FormatInt(t.Unix(), 10))
+		case 'S':
+			fmt.Fprintf(&b, "%02d", t.Second())
+		case 't':
+			b.WriteByte('\t')
+		case 'T', 'X':
+			b.WriteString(t.Format("15:04:05"))
+		case 'u':
+			fmt.Fprintf(&b, "%d", (int(t.Weekday())+6)%7+1)
+		case 'U':
+			// Weeks starting on Sunday; days before the first Sunday are in week 0.
+			fmt.Fprintf(&b, "%02d", (t.YearDay()+6-int(t.Weekday()))/7)
+		case 'V':
+			_, week := t.ISOWeek()
+			fmt.Fprintf(&b, "%02d", week)
+		case 'w':
+			fmt.Fprintf(&b, "%d", int(t.Weekday()))
+		case 'W':
+			// Weeks starting on Monday; days before the first Monday are in week 0.
+			fmt.Fprintf(&b, "%02d", (t.YearDay()+6-(int(t.Weekday())+6)%7)/7)
+		case 'y':
+			fmt.Fprintf(&b, "%02d", t.Year()%100)
+		case 'Y':
+			fmt.Fprintf(&b, "%d", t.Year())
+		case 'z':
+			b.WriteString(t.Format("-0700"))
+		case 'Z':
+			b.WriteString(t.Format("MST"))
+		case '%':
+			b.WriteByte('%')
+		default:
+			b.WriteString(format[i-1 : i+1])
+			if err == nil {
+				err = &FuncError{Func: fn, Err: fmt.Errorf("unknown directive %%%c in %q", format[i], format)}
+			}
+		}
+	}
+	return b.String(), err
+}
+
+// strptimeLayouts maps the strftime directives that can be parsed to Go
+// layout elements.
+var strptimeLayouts = map[byte]string{
+	'a': "Mon",
+	'A': "Monday",
+	'b': "Jan",
+	'h': "Jan",
+	'B': "January",
+	'd': "02",
+	'D': "01/02/06",
+	'e': "_2",
+	'F': "2006-01-02",
+	'H': "15",
+	'I': "03",
+	'j': "002",
+	'm': "01",
+	'M': "04",
+	'p': "PM",
+	'R': "15:04",
+	'S': "05",
+	'T': "15:04:05",
+	'y': "06",
+	'Y': "2006",
+	'z': "-0700",
+	'Z': "MST",
+}
+
+// strptimeReserved lists the text that has a meaning in Go layouts and so
+// cannot appear literally in a strptime pattern.
+var strptimeReserved = []string{"Jan", "Mon", "MST", "PM", "pm", "Z07", "0", "1", "2", "3", "4", "5", "6", "7", "8", "9"}
+
+// strptime parses a date with a strftime pattern or one of the datePresets,
+// ignoring errors. Dates without a time zone are in the local time zone. It
+// returns the zero time if the date cannot be parsed.
+func strptime(format string, str string) time.Time {
+	t, _ := mustStrptime(format, str)
+	return t
+}
+
+// mustStrptime is like strptime, but returns an error if the date cannot be
+// parsed.
+func mustStrptime(format string, str string) (time.Time, error) {
+	const fn = "strptime"
+	if format == "%s" {
+		n, err := strconv.ParseInt(strings.TrimSpace(str), 10, 64)
+		if err != nil {
+			return time.Time{}, &ParseError{Func: fn, Input: str, Err: err}
+		}
+		return time.Unix(n, 0), nil
+	}
+	layout, err := strptimeLayout(format)
+	if err != nil {
+		return time.Time{}, &FuncError{Func: fn, Err: err}
+	}
+	t, err := time.ParseInLocation(layout, str, time.Local)
+	if err != nil {
+		return time.Time{}, &ParseError{Func: fn, Input: str, Err: err}
+	}
+	return t, nil
+}
+
+// strptimeLayout converts a strftime pattern or preset to a Go layout.
+func strptimeLayout(format string) (string, error) {
+	if layout, ok := datePresets[strings.ToLower(format)]; ok {
+		return layout, nil
+	}
+
+	var b strings.Builder
+	literal := func(s string) error {
+		for _, r := range strptimeReserved {
+			if strings.Contains(s, r) {
+				return fmt.Errorf("literal text %q cannot be parsed in %q", s, format)
+			}
+		}
+		b.WriteString(s)
+		return nil
+	}
+	start := 0
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			continue
+		}
+		if err := literal(format[start:i]); err != nil {
+			return "", err
+		}
+		if i == len(format)-1 {
+			return "", errors.New("pattern ends with %")
+		}
+		i++
+		switch c := format[i]; c {
+		case '%':
+			b.WriteByte('%')
+		case 'n':
+			b.WriteByte('\n')
+		case 't':
+			b.WriteByte('\t')
+		case 'f':
+			// Go parses a fraction after the seconds without a layout
+			// element for it, so drop the separator and let it do that.
+			s := b.String()
+			if !strings.HasSuffix(s, "05.") && !strings.HasSuffix(s, "05,") {
+				return "", fmt.Errorf("%%f must follow %%S and a period in %q", format)
+			}
+			b.Reset()
+			b.WriteString(s[:len(s)-1])
+		default:
+			layout, ok := strptimeLayouts[c]
+			if !ok {
+				return "", fmt.Errorf("directive %%%c cannot be parsed", c)
+			}
+			b.WriteString(layout)
+		}
+		start = i + 1
+	}
+	if err := literal(format[start:]); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
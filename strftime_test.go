@@ -0,0 +1,91 @@
+package sprig
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStrftime(t *testing.T) {
+	// A Tuesday, in the first ISO week of 2019.
+	tm := time.Date(2019, 1, 1, 15, 4, 5, 123456789, time.FixedZone("CET", 3600))
+	tests := map[string]string{
+		"%Y-%m-%d %H:%M:%S":   "2019-01-01 15:04:05",
+		"%a %A %b %h %B":      "Tue Tuesday Jan Jan January",
+		"%c":                  "Tue Jan  1 15:04:05 2019",
+		"%C %y %G %g %V":      "20 19 2019 19 01",
+		"%D %x %F":            "01/01/19 01/01/19 2019-01-01",
+		"[%e] [%k] [%l]":      "[ 1] [15] [ 3]",
+		"%I %p %P %r":         "03 PM pm 03:04:05 PM",
+		"%j %U %W %u %w":      "001 00 00 2 2",
+		"%R %T %X":            "15:04 15:04:05 15:04:05",
+		"%S.%f":               "05.123456",
+		"%s":                  "1546351445",
+		"%z %Z":               "+0100 CET",
+		"100%% %n%t":          "100% \n\t",
+		"%Q %":                "%Q %",
+		"iso8601":             "2019-01-01T15:04:05+01:00",
+		"RFC3339":             "2019-01-01T15:04:05+01:00",
+		"rfc1123":             "Tue, 01 Jan 2019 15:04:05 CET",
+		"kitchen":             "3:04PM",
+		"datetime":            "2019-01-01 15:04:05",
+		"week %U of %Y (%a)":  "week 00 of 2019 (Tue)",
+		"day %j, ISO week %V": "day 001, ISO week 01",
+	}
+	for format, want := range tests {
+		assert.Equal(t, want, systemClock.strftime(format, tm), format)
+	}
+
+	// Sunday 2019-01-06 starts week 1 with %U, Monday 2019-01-07 with %W.
+	sun := time.Date(2019, 1, 6, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, "01 00 0 7", systemClock.strftime("%U %W %w %u", sun))
+	assert.Equal(t, "01 01", systemClock.strftime("%U %W", sun.AddDate(0, 0, 1)))
+	assert.Equal(t, "2020-W53", systemClock.strftime("%G-W%V", time.Date(2021, 1, 3, 0, 0, 0, 0, time.UTC)))
+
+	assert.NoError(t, runt(`{{ strftime "%d/%m/%Y" "2017-12-31T10:00:00Z" }}`, "31/12/2017"))
+	assert.NoError(t, runt(`{{ "1514714400" | dateFormat "%Y-%m-%dT%H:%M:%S%z" | len }}`, "24"))
+
+	_, err := systemClock.mustStrftime("%Y %Q", tm)
+	assert.ErrorContains(t, err, "strftime: unknown directive %Q")
+	_, err = runRaw(`{{ mustDateFormat "%Y" "not a date" }}`, nil)
+	assert.ErrorContains(t, err, "strftime: unrecognized date format")
+}
+
+func TestStrptime(t *testing.T) {
+	tests := []struct {
+		format, input string
+		want          time.Time
+	}{
+		{"%Y-%m-%d %H:%M:%S", "2019-06-13 20:39:39", time.Date(2019, 6, 13, 20, 39, 39, 0, time.Local)},
+		{"%d/%b/%Y:%H:%M:%S %z", "13/Jun/2019:20:39:39 +0000", time.Date(2019, 6, 13, 20, 39, 39, 0, time.UTC)},
+		{"%A, %B %e %Y %I:%M %p", "Thursday, June 13 2019 08:39 PM", time.Date(2019, 6, 13, 20, 39, 0, 0, time.Local)},
+		{"%Y%m%dT%H%M%S", "20190613T203939", time.Date(2019, 6, 13, 20, 39, 39, 0, time.Local)},
+		{"%Y-%j", "2019-164", time.Date(2019, 6, 13, 0, 0, 0, 0, time.Local)},
+		{"%T.%f", "20:39:39.25", time.Date(0, 1, 1, 20, 39, 39, 250000000, time.Local)},
+		{"%D %R", "06/13/19 20:39", time.Date(2019, 6, 13, 20, 39, 0, 0, time.Local)},
+		{"%s", "1560458379", time.Unix(1560458379, 0)},
+		{"iso8601", "2019-06-13T20:39:39Z", time.Date(2019, 6, 13, 20, 39, 39, 0, time.UTC)},
+		{"RFC1123Z", "Thu, 13 Jun 2019 20:39:39 +0000", time.Date(2019, 6, 13, 20, 39, 39, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		got, err := mustStrptime(tt.format, tt.input)
+		if assert.NoError(t, err, tt.format) {
+			assert.True(t, tt.want.Equal(got), "%s: got %s, want %s", tt.format, got, tt.want)
+		}
+	}
+
+	for _, format := range []string{"%Y %U", "%Y at 5", "%Y %", "%f", "%Y Jan %d"} {
+		_, err := mustStrptime(format, "2019")
+		var ferr *FuncError
+		assert.ErrorAs(t, err, &ferr, format)
+	}
+	_, err := mustStrptime("%Y-%m-%d", "2019-13-01")
+	var perr *ParseError
+	if assert.ErrorAs(t, err, &perr) {
+		assert.Equal(t, "strptime", perr.Func)
+	}
+	assert.True(t, strptime("%Y-%m-%d", "yesterday").IsZero())
+
+	assert.NoError(t, runt(`{{ strptime "%d.%m.%Y" "31.12.2017" | strftime "%F" }}`, "2017-12-31"))
+}
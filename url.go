@@ -6,24 +6,33 @@ import (
 	"reflect"
 )
 
-func dictGetOrEmpty(dict map[string]interface{}, key string) string {
+func dictGetOrEmpty(dict map[string]interface{}, key string) (string, error) {
 	value, ok := dict[key]
 	if !ok {
-		return ""
+		return "", nil
 	}
-	tp := reflect.TypeOf(value).Kind()
-	if tp != reflect.String {
-		panic(fmt.Sprintf("unable to parse %s key, must be of type string, but %s found", key, tp.String()))
+	rv := reflect.ValueOf(value)
+	if rv.Kind() != reflect.String {
+		return "", &TypeError{Func: "urlJoin", Arg: fmt.Sprintf("key %q", key), Got: typeName(value), Want: "string"}
 	}
-	return reflect.ValueOf(value).String()
+	return rv.String(), nil
 }
 
 // parses given URL to return dict object
 func urlParse(v string) map[string]interface{} {
+	dict, err := mustUrlParse(v)
+	if err != nil {
+		panic(err)
+	}
+	return dict
+}
+
+// parses given URL to return dict object, returning errors
+func mustUrlParse(v string) (map[string]interface{}, error) {
 	dict := map[string]interface{}{}
 	parsedURL, err := url.Parse(v)
 	if err != nil {
-		panic(fmt.Sprintf("unable to parse url: %s", err))
+		return nil, &ParseError{Func: "urlParse", Input: v, Err: err}
 	}
 	dict["scheme"] = parsedURL.Scheme
 	dict["host"] = parsedURL.Host
@@ -38,29 +47,45 @@ func urlParse(v string) map[string]interface{} {
 		dict["userinfo"] = ""
 	}
 
-	return dict
+	return dict, nil
 }
 
 // join given dict to URL string
 func urlJoin(d map[string]interface{}) string {
+	s, err := mustUrlJoin(d)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// join given dict to URL string, returning errors
+func mustUrlJoin(d map[string]interface{}) (string, error) {
+	parts := map[string]string{}
+	for _, key := range []string{"scheme", "host", "path", "query", "opaque", "fragment", "userinfo"} {
+		value, err := dictGetOrEmpty(d, key)
+		if err != nil {
+			return "", err
+		}
+		parts[key] = value
+	}
 	resURL := url.URL{
-		Scheme:   dictGetOrEmpty(d, "scheme"),
-		Host:     dictGetOrEmpty(d, "host"),
-		Path:     dictGetOrEmpty(d, "path"),
-		RawQuery: dictGetOrEmpty(d, "query"),
-		Opaque:   dictGetOrEmpty(d, "opaque"),
-		Fragment: dictGetOrEmpty(d, "fragment"),
+		Scheme:   parts["scheme"],
+		Host:     parts["host"],
+		Path:     parts["path"],
+		RawQuery: parts["query"],
+		Opaque:   parts["opaque"],
+		Fragment: parts["fragment"],
 	}
-	userinfo := dictGetOrEmpty(d, "userinfo")
 	var user *url.Userinfo
-	if userinfo != "" {
-		tempURL, err := url.Parse(fmt.Sprintf("proto://%s@host", userinfo))
+	if parts["userinfo"] != "" {
+		tempURL, err := url.Parse(fmt.Sprintf("proto://%s@host", parts["userinfo"]))
 		if err != nil {
-			panic(fmt.Sprintf("unable to parse userinfo in dict: %s", err))
+			return "", &ParseError{Func: "urlJoin", Input: parts["userinfo"], Err: err}
 		}
 		user = tempURL.User
 	}
 
 	resURL.User = user
-	return resURL.String()
+	return resURL.String(), nil
 }
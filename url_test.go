@@ -85,3 +85,30 @@ func TestUrlJoin(t *testing.T) {
 	}
 
 }
+
+func TestMustUrlParse(t *testing.T) {
+	assert.NoError(t, runt(`{{ (mustUrlParse "proto://host:80/path").hostname }}`, "host"))
+
+	_, err := mustUrlParse("proto://host:port")
+	var parseErr *ParseError
+	if assert.ErrorAs(t, err, &parseErr) {
+		assert.Equal(t, "urlParse", parseErr.Func)
+		assert.Equal(t, "proto://host:port", parseErr.Input)
+	}
+	assert.Error(t, runt(`{{ mustUrlParse "proto://host:port" }}`, ""))
+}
+
+func TestMustUrlJoin(t *testing.T) {
+	for expected, urlMap := range urlTests {
+		actual, err := mustUrlJoin(urlMap)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, actual)
+	}
+
+	_, err := mustUrlJoin(map[string]interface{}{"host": 80})
+	var typeErr *TypeError
+	if assert.ErrorAs(t, err, &typeErr) {
+		assert.Equal(t, &TypeError{Func: "urlJoin", Arg: `key "host"`, Got: "int", Want: "string"}, typeErr)
+	}
+	assert.EqualError(t, err, `urlJoin: key "host" must be string, got int`)
+}